@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gin_main/internal/services"
+	"gin_main/pkg/tabular"
+)
+
+// runImport выполняет подкоманду "import [-dry-run] [-format csv|xlsx] <file>" и печатает отчёт в out
+func runImport(ctx context.Context, importService services.ImportServiceInterface, out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only validate rows, do not write anything")
+	format := flags.String("format", "", "csv or xlsx, by default taken from the file extension")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: import [-dry-run] [-format csv|xlsx] <file>")
+	}
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = tabular.FormatFromName(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, inError := importService.Import(ctx, file, *format, *dryRun)
+	if inError != nil {
+		return fmt.Errorf("import failed: %w", inError)
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", report.Failed, report.Total)
+	}
+	return nil
+}
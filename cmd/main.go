@@ -3,60 +3,164 @@ package main
 // https://gin-gonic.com/docs/
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"time"
 
 	"gin_main/config"
+	"gin_main/internal/handlers"
+	"gin_main/internal/jwt"
+	"gin_main/internal/migrations"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/services"
 	"gin_main/pkg/database"
 	"gin_main/pkg/httpserver"
 	"gin_main/pkg/httpserver/middlewares"
 	"gin_main/pkg/httpserver/router"
 	"gin_main/pkg/logger"
-	"gin_main/src/database/migrations"
-	"gin_main/src/database/repositories"
-	"gin_main/src/handlers"
-	"gin_main/src/jwt"
-	"gin_main/src/services"
+	"gin_main/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
-	"github.com/rs/zerolog"
 )
 
 func main() {
-	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	config := config.NewConfig()
-	log := logger.NewLogger(zerolog.InfoLevel)
+	log := logger.NewLogger(config)
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrations.Run(config, log, os.Stdout, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Migration command failed")
+		}
+		return
+	}
 	migrations.Migrate(config, log)
 
-	engine := gin.Default()
+	shutdownTracing, err := tracing.Setup(context.Background(), config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot set up tracing")
+	}
+
+	// вместо gin.Default: запросы пишет LogContextMiddleware, а паника отвечает problem+json
+	engine := gin.New()
 	server := httpserver.NewServer(log, engine, config)
 
 	db := database.NewDatabaseConnection(config)
+	importService := services.NewImportService(repositories.NewImportRepository(db))
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeouts.Import)
+		defer cancel()
+		if err := runImport(ctx, importService, os.Stdout, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Import command failed")
+		}
+		return
+	}
+
+	if err := database.NewTracing(db); err != nil {
+		log.Fatal().Err(err).Msg("Cannot set up database tracing")
+	}
+	dbMetrics, err := database.NewMetrics(db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot set up database metrics")
+	}
+	server.AddMetrics(dbMetrics...)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot get database handle")
+	}
+	migrator, err := migrations.NewMigrator(sqlDB, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cannot load migrations")
+	}
+	server.AddReadinessCheck("database", sqlDB.PingContext)
+	server.AddReadinessCheck("migrations", migrator.CheckVersion)
+
 	bookRepo := repositories.NewBookRepository(db)
 	bookService := services.NewBookService(bookRepo)
-	bookHandler := handlers.NewBookHandler(bookService)
+	idempotency := middlewares.IdempotencyMiddleware(repositories.NewIdempotencyRepository(db), config.Idempotency.TTL, handlers.MaxBookBodySize)
+	bookHandler := handlers.NewBookHandler(bookService, idempotency)
+
+	editionRepo := repositories.NewEditionRepository(db)
+	editionService := services.NewEditionService(editionRepo)
+	editionHandler := handlers.NewEditionHandler(editionService)
 
 	authorRepo := repositories.NewAuthorRepository(db)
 	authorService := services.NewAuthorService(authorRepo)
 	authorHandler := handlers.NewAuthorHandler(authorService)
 
-	userRepo := repositories.NewUserRepository()
+	importHandler := handlers.NewImportHandler(importService, config.Timeouts.Import)
+
+	trashRepo := repositories.NewTrashRepository(db)
+	trashService := services.NewTrashService(trashRepo)
+	trashHandler := handlers.NewTrashHandler(trashService)
+
+	searchRepo := repositories.NewSearchRepository(db)
+	searchService := services.NewSearchService(searchRepo)
+	searchHandler := handlers.NewSearchHandler(searchService)
+
+	warehouseRepo := repositories.NewWarehouseRepository(db)
+	warehouseService := services.NewWarehouseService(warehouseRepo)
+	warehouseHandler := handlers.NewWarehouseHandler(warehouseService)
+
+	reservationRepo := repositories.NewReservationRepository(db)
+	reservationService := services.NewReservationService(reservationRepo, config.Reservations.TTL)
+	reservationHandler := handlers.NewReservationHandler(reservationService)
+	server.AddWorker("reservation-sweeper", services.NewReservationSweeper(reservationService, config.Reservations.SweepInterval, log))
+
+	orderRepo := repositories.NewOrderRepository(db)
+	orderService := services.NewOrderService(orderRepo, config.Orders.ReservationTTL)
+	orderHandler := handlers.NewOrderHandler(orderService)
+
+	purchaseOrderRepo := repositories.NewPurchaseOrderRepository(db)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepo)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService)
+
+	stockMovementRepo := repositories.NewStockMovementRepository(db)
+	server.AddMetrics(services.NewStockMetrics(bookRepo, stockMovementRepo))
+	stockMovementService := services.NewStockMovementService(stockMovementRepo)
+	stockMovementHandler := handlers.NewStockMovementHandler(stockMovementService)
+
+	userRepo := repositories.NewUserRepository(db)
 	userService := services.NewUserService(userRepo)
 	userHandler := handlers.NewUserHandler(userService)
+	if config.Auth.AdminPassword != "" {
+		if err := userService.EnsureUser(context.Background(), config.Auth.AdminLogin, config.Auth.AdminPassword, models.RoleAdmin); err != nil {
+			log.Fatal().Err(err).Msg("Cannot create admin user")
+		}
+	}
 
 	jwtHelper := jwt.NewJWTHelper(config)
-	authService := services.NewAuthService(jwtHelper, userService)
+	authService := services.NewAuthService(jwtHelper, userService, repositories.NewRevokedTokenRepository(db))
 	authHandler := handlers.NewAuthHandler(authService)
 
+	server.AddMiddleware(middlewares.TracingMiddleware())
 	server.AddMiddleware(middlewares.LogContextMiddleware(server.GetLogger()))
+	server.AddMiddleware(middlewares.RecoveryMiddleware())
 	//server.AddMiddleware(middlewares.BearerAuthMiddleware(authService))
 
+	// срок задаётся до регистрации маршрутов: gin добавляет к маршруту только уже подключённые middleware
+	engine.Use(middlewares.TimeoutMiddleware(config.Timeouts.Request, map[string]time.Duration{
+		"/api/v1/books/export": config.Timeouts.Export,
+		"/api/v1/books/import": config.Timeouts.Import,
+	}))
+
 	router.RegisterPublicEndpoints(engine, authHandler)
 	router.RegisterProtectedEndpoints(engine, authService, bookHandler)
+	router.RegisterProtectedEndpoints(engine, authService, editionHandler)
+	router.RegisterProtectedEndpoints(engine, authService, importHandler)
 	router.RegisterProtectedEndpoints(engine, authService, authorHandler)
+	router.RegisterProtectedEndpoints(engine, authService, trashHandler)
+	router.RegisterProtectedEndpoints(engine, authService, searchHandler)
 	router.RegisterProtectedEndpoints(engine, authService, userHandler)
+	router.RegisterProtectedEndpoints(engine, authService, warehouseHandler)
+	router.RegisterProtectedEndpoints(engine, authService, stockMovementHandler)
+	router.RegisterProtectedEndpoints(engine, authService, reservationHandler)
+	router.RegisterProtectedEndpoints(engine, authService, orderHandler)
+	router.RegisterProtectedEndpoints(engine, authService, purchaseOrderHandler)
 
 	engine.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -65,6 +169,13 @@ func main() {
 	})
 	engine.POST("/try", SomeHandler)
 	server.Serve()
+
+	// после остановки сервера отправляем спаны, ещё не ушедшие в экспортёр
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Tracing shutdown")
+	}
 }
 
 func SomeHandler(c *gin.Context) {
@@ -3,8 +3,10 @@ package config
 import (
 	_ "embed"
 	"errors"
+	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,14 +14,28 @@ import (
 )
 
 const (
-	envBookDB = "PG_BOOK"
+	envBookDB            = "PG_BOOK"
+	envJWTAccessSecret   = "JWT_ACCESS_SECRET"
+	envJWTRefreshSecret  = "JWT_REFRESH_SECRET"
+	envAuthAdminPassword = "AUTH_ADMIN_PASSWORD"
+	envLogLevel          = "LOG_LEVEL"
+	envTracingExporter   = "TRACING_EXPORTER"
+
+	minJWTSecretLength = 32 // байт в ключе HMAC-SHA256, короче ключ можно подобрать перебором
 )
 
 type Config struct {
-	App      string         `yaml:"app"`
-	Stack    string         `yaml:"stack"`
-	Server   serverConfig   `yaml:"server"`
-	Database databaseConfig `yaml:"database"`
+	App          string             `yaml:"app"`
+	Stack        string             `yaml:"stack"`
+	Server       serverConfig       `yaml:"server"`
+	Database     databaseConfig     `yaml:"database"`
+	Auth         authConfig         `yaml:"auth"`
+	Idempotency  idempotencyConfig  `yaml:"idempotency"`
+	Reservations reservationsConfig `yaml:"reservations"`
+	Orders       ordersConfig       `yaml:"orders"`
+	Timeouts     timeoutsConfig     `yaml:"timeouts"`
+	Logger       loggerConfig       `yaml:"logger"`
+	Tracing      tracingConfig      `yaml:"tracing"`
 }
 
 type serverConfig struct {
@@ -28,10 +44,55 @@ type serverConfig struct {
 	ReadTimeout       time.Duration `yaml:"read_timeout"`
 	WriteTimeout      time.Duration `yaml:"write_timeout"`
 	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	DrainDelay        time.Duration `yaml:"drain_delay"` // сколько после SIGTERM /readyz отвечает 503 до остановки, чтобы балансировщик успел снять трафик
 }
 
 type databaseConfig struct {
-	BookDB string `yaml:"bookDB"`
+	BookDB    string        `yaml:"bookDB"`
+	SlowQuery time.Duration `yaml:"slow_query"` // запросы дольше этого срока пишутся в лог с уровнем warn
+}
+
+type idempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl"` // сколько хранится ответ на запрос с Idempotency-Key
+}
+
+type reservationsConfig struct {
+	TTL           time.Duration `yaml:"ttl"`            // через сколько неподтверждённый резерв истекает
+	SweepInterval time.Duration `yaml:"sweep_interval"` // как часто фоновая задача помечает просроченные резервы
+}
+
+type ordersConfig struct {
+	ReservationTTL time.Duration `yaml:"reservation_ttl"` // срок резерва, создаваемого при переводе заказа в сборку
+}
+
+// timeoutsConfig ограничивает время операций; по истечении срока отменяется контекст, и запросы к базе прерываются
+type timeoutsConfig struct {
+	Request   time.Duration `yaml:"request"`   // обычный запрос API вместе со всеми обращениями к базе
+	Export    time.Duration `yaml:"export"`    // потоковая выгрузка каталога
+	Import    time.Duration `yaml:"import"`    // импорт каталога из файла, через API и из командной строки
+	Readiness time.Duration `yaml:"readiness"` // каждая проверка /readyz: пинг базы, версия миграций
+}
+
+type loggerConfig struct {
+	Level  string `yaml:"level"`  // trace, debug, info, warn или error
+	Format string `yaml:"format"` // json для сбора логов, console для чтения в терминале
+}
+
+// tracingConfig задаёт, куда отправляются трассы OpenTelemetry
+type tracingConfig struct {
+	Exporter    string  `yaml:"exporter"`     // none - трассировка выключена, stdout - вывод в консоль для отладки, otlp - коллектор по OTLP/HTTP
+	Endpoint    string  `yaml:"endpoint"`     // адрес коллектора host:port для otlp; пустой - из OTEL_EXPORTER_OTLP_ENDPOINT или localhost:4318
+	Insecure    bool    `yaml:"insecure"`     // otlp без TLS, например к коллектору рядом с сервисом
+	SampleRatio float64 `yaml:"sample_ratio"` // доля новых трасс, которые записываются; решение вызывающего из traceparent соблюдается всегда
+}
+
+type authConfig struct {
+	AccessSecret  string        `yaml:"access_secret"`  // задаётся только через JWT_ACCESS_SECRET
+	RefreshSecret string        `yaml:"refresh_secret"` // задаётся только через JWT_REFRESH_SECRET
+	AccessTTL     time.Duration `yaml:"access_ttl"`
+	RefreshTTL    time.Duration `yaml:"refresh_ttl"`
+	AdminLogin    string        `yaml:"admin_login"`    // учётная запись, создаваемая при старте, если её ещё нет
+	AdminPassword string        `yaml:"admin_password"` // пустой пароль отключает создание
 }
 
 var (
@@ -49,6 +110,26 @@ func NewConfig() *Config {
 		log.Println("Database variable found")
 		cfg.Database.BookDB = envVal
 	}
+	if envVal, exist := os.LookupEnv(envJWTAccessSecret); exist {
+		log.Println("JWT access secret variable found")
+		cfg.Auth.AccessSecret = envVal
+	}
+	if envVal, exist := os.LookupEnv(envJWTRefreshSecret); exist {
+		log.Println("JWT refresh secret variable found")
+		cfg.Auth.RefreshSecret = envVal
+	}
+	if envVal, exist := os.LookupEnv(envAuthAdminPassword); exist {
+		log.Println("Admin password variable found")
+		cfg.Auth.AdminPassword = envVal
+	}
+	if envVal, exist := os.LookupEnv(envLogLevel); exist {
+		log.Println("Log level variable found")
+		cfg.Logger.Level = envVal
+	}
+	if envVal, exist := os.LookupEnv(envTracingExporter); exist {
+		log.Println("Tracing exporter variable found")
+		cfg.Tracing.Exporter = envVal
+	}
 	if err := validate(cfg); err != nil {
 		log.Fatal("Wrong configuration", err)
 	}
@@ -61,6 +142,36 @@ func validate(cfg *Config) error {
 		return errors.New("bookDB connection string is empty")
 	case cfg.Server.Port == 0:
 		return errors.New("server port is zero")
+	case cfg.Auth.AccessSecret == "" || cfg.Auth.RefreshSecret == "":
+		return errors.New("jwt secrets are empty: set " + envJWTAccessSecret + " and " + envJWTRefreshSecret)
+	case len(cfg.Auth.AccessSecret) < minJWTSecretLength || len(cfg.Auth.RefreshSecret) < minJWTSecretLength:
+		return fmt.Errorf("jwt secrets must be at least %d bytes long", minJWTSecretLength)
+	case cfg.Auth.AccessSecret == cfg.Auth.RefreshSecret:
+		return errors.New("jwt access and refresh secrets must differ")
+	case cfg.Auth.AccessTTL <= 0 || cfg.Auth.RefreshTTL <= 0:
+		return errors.New("jwt token ttl must be positive")
+	case cfg.Idempotency.TTL <= 0:
+		return errors.New("idempotency ttl must be positive")
+	case cfg.Reservations.TTL <= 0 || cfg.Reservations.SweepInterval <= 0:
+		return errors.New("reservation ttl and sweep interval must be positive")
+	case cfg.Orders.ReservationTTL <= 0:
+		return errors.New("order reservation ttl must be positive")
+	case cfg.Timeouts.Request <= 0 || cfg.Timeouts.Export <= 0 || cfg.Timeouts.Import <= 0:
+		return errors.New("request, export and import timeouts must be positive")
+	case cfg.Timeouts.Readiness <= 0:
+		return errors.New("readiness timeout must be positive")
+	case cfg.Server.DrainDelay < 0:
+		return errors.New("server drain delay must not be negative")
+	case cfg.Database.SlowQuery <= 0:
+		return errors.New("slow query threshold must be positive")
+	case !slices.Contains([]string{"trace", "debug", "info", "warn", "error"}, cfg.Logger.Level):
+		return errors.New("log level must be one of trace, debug, info, warn, error")
+	case cfg.Logger.Format != "json" && cfg.Logger.Format != "console":
+		return errors.New("log format must be json or console")
+	case !slices.Contains([]string{"none", "stdout", "otlp"}, cfg.Tracing.Exporter):
+		return errors.New("tracing exporter must be one of none, stdout, otlp")
+	case cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1:
+		return errors.New("tracing sample ratio must be between 0 and 1")
 	default:
 		return nil
 	}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandlerInterface interface {
+	router.HandlerInterface
+	Login(ctx *gin.Context)
+	Refresh(ctx *gin.Context)
+	Logout(ctx *gin.Context)
+}
+
+type authHandler struct {
+	authService services.AuthServiceInterface
+}
+
+func NewAuthHandler(authService services.AuthServiceInterface) AuthHandlerInterface {
+	return &authHandler{authService: authService}
+}
+
+func (h *authHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/auth/login", h.Login)
+	router.POST("/auth/refresh", h.Refresh)
+	router.POST("/auth/logout", h.Logout)
+}
+
+func (h *authHandler) Login(ctx *gin.Context) {
+	var loginRequest models.LoginRequest
+	if err := ctx.ShouldBindJSON(&loginRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	tokens, inError := h.authService.Login(ctx.Request.Context(), loginRequest)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func (h *authHandler) Refresh(ctx *gin.Context) {
+	var refreshRequest models.RefreshRequest
+	if err := ctx.ShouldBindJSON(&refreshRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	tokens, inError := h.authService.Refresh(ctx.Request.Context(), refreshRequest)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func (h *authHandler) Logout(ctx *gin.Context) {
+	var logoutRequest models.RefreshRequest
+	if err := ctx.ShouldBindJSON(&logoutRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	if inError := h.authService.Logout(ctx.Request.Context(), logoutRequest); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuthorHandlerInterface interface {
+	router.HandlerInterface
+	CreateAuthor(ctx *gin.Context)
+	UpdateAuthor(ctx *gin.Context)
+	FindAuthorById(ctx *gin.Context)
+	GetAllAuthors(ctx *gin.Context)
+	DeleteAuthor(ctx *gin.Context)
+	GetAuthorBooks(ctx *gin.Context)
+}
+
+type authorHandler struct {
+	authorService services.AuthorServiceInterface
+}
+
+func NewAuthorHandler(authorService services.AuthorServiceInterface) AuthorHandlerInterface {
+	return &authorHandler{authorService: authorService}
+}
+
+func (h *authorHandler) RegisterRoutes(router *gin.RouterGroup) {
+	writers := middlewares.RequireRole(models.RoleClerk, models.RoleManager)
+	managers := middlewares.RequireRole(models.RoleManager)
+
+	router.POST("/authors", writers, h.CreateAuthor)
+	router.GET("/authors", h.GetAllAuthors)
+	router.GET("/authors/:id", h.FindAuthorById)
+	router.PUT("/authors/:id", writers, h.UpdateAuthor)
+	router.DELETE("/authors/:id", managers, h.DeleteAuthor)
+	router.GET("/authors/:id/books", h.GetAuthorBooks)
+}
+
+func (h *authorHandler) CreateAuthor(ctx *gin.Context) {
+	var createAuthorRequest models.CreateOrUpdateAuthorRequest
+	if err := ctx.ShouldBindJSON(&createAuthorRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	createAuthorResponse, inError := h.authorService.Create(ctx.Request.Context(), createAuthorRequest)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, createAuthorResponse)
+}
+
+func (h *authorHandler) UpdateAuthor(ctx *gin.Context) {
+	authorID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("author"))
+		return
+	}
+	var updateAuthorRequest models.CreateOrUpdateAuthorRequest
+	if err := ctx.ShouldBindJSON(&updateAuthorRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	// для авторов If-Match необязателен: без него изменение применяется к текущей версии
+	version, _, err := ifMatchVersion(ctx)
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, err)
+		return
+	}
+	newVersion, inError := h.authorService.Update(ctx.Request.Context(), authorID, updateAuthorRequest, version)
+	if inError != nil {
+		if current, ok := apperrors.From(inError).Details.(models.Author); ok {
+			ctx.Header("ETag", versionETag(current.Version))
+		}
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Header("ETag", versionETag(newVersion))
+	ctx.Status(http.StatusOK)
+}
+
+func (h *authorHandler) FindAuthorById(ctx *gin.Context) {
+	authorID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("author"))
+		return
+	}
+	author, inError := h.authorService.FindById(ctx.Request.Context(), authorID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Header("ETag", versionETag(author.Version))
+	ctx.JSON(http.StatusOK, author)
+}
+
+func (h *authorHandler) GetAllAuthors(ctx *gin.Context) {
+	authors, inError := h.authorService.GetAll(ctx.Request.Context())
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, authors)
+}
+
+func (h *authorHandler) DeleteAuthor(ctx *gin.Context) {
+	authorID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("author"))
+		return
+	}
+	if inError := h.authorService.Delete(ctx.Request.Context(), authorID, actorFromContext(ctx)); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *authorHandler) GetAuthorBooks(ctx *gin.Context) {
+	authorID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("author"))
+		return
+	}
+	books, inError := h.authorService.GetBooks(ctx.Request.Context(), authorID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, books)
+}
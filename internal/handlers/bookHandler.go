@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"gin_main/internal/models"
 	"gin_main/internal/services"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
 	"gin_main/pkg/httpserver/router"
 
 	"github.com/gin-gonic/gin"
@@ -17,48 +23,92 @@ type BookHandlerInterface interface {
 	CreateBook(ctx *gin.Context)
 	UpdateBook(ctx *gin.Context)
 	FindBookById(ctx *gin.Context)
+	FindBookByISBN(ctx *gin.Context)
 	FindBookByParameters(ctx *gin.Context)
 	GetAllBooks(ctx *gin.Context)
+	ExportBooks(ctx *gin.Context)
 	ChangeQuantity(ctx *gin.Context)
+	DeleteBook(ctx *gin.Context)
 }
 
+// MaxBookBodySize ограничивает тело запросов создания книги и изменения остатка; с таким пределом
+// создаётся middleware идемпотентности, которое читает тело целиком и сохраняет его
+const MaxBookBodySize = 1 << 20
+
+const (
+	exportFlushEvery    = 100              // через сколько строк экспорт отправляет накопленное клиенту
+	exportWriteDeadline = 30 * time.Second // после каждой отправки срок записи продлевается, иначе write_timeout сервера оборвёт выгрузку
+)
+
+var exportCSVHeader = []string{"bookId", "title", "isbn", "year", "contributors", "onHand", "reserved", "available"}
+
 type bookHandler struct {
 	bookService services.BookServiceInterface
+	idempotency gin.HandlerFunc // повтор запроса с тем же Idempotency-Key не создаёт книгу и не меняет остаток дважды
 }
 
-func NewBookHandler(bookService services.BookServiceInterface) BookHandlerInterface {
-	return &bookHandler{bookService: bookService}
+func NewBookHandler(bookService services.BookServiceInterface, idempotency gin.HandlerFunc) BookHandlerInterface {
+	return &bookHandler{bookService: bookService, idempotency: idempotency}
 }
 
 func (h *bookHandler) RegisterRoutes(router *gin.RouterGroup) {
+	writers := middlewares.RequireRole(models.RoleClerk, models.RoleManager)
+	managers := middlewares.RequireRole(models.RoleManager)
+
+	router.POST("/books", writers, h.idempotency, h.CreateBook)
 	router.GET("/books", h.GetAllBooks)
+	router.GET("/books/search", h.FindBookByParameters)
+	router.GET("/books/export", h.ExportBooks)
+	router.GET("/books/isbn/:isbn", h.FindBookByISBN)
+	router.GET("/books/:id", h.FindBookById)
+	router.PUT("/books/:id", writers, h.UpdateBook)
+	router.PATCH("/books/:id/quantity", middlewares.RequireRole(models.RoleClerk), h.idempotency, h.ChangeQuantity)
+	router.DELETE("/books/:id", managers, h.DeleteBook)
 }
 
 func (h *bookHandler) CreateBook(ctx *gin.Context) {
 	var createBookRequest models.CreateOrUpdateBookRequest
 	if err := ctx.ShouldBindJSON(&createBookRequest); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
 		return
 	}
-	createBookResponse, inError := h.bookService.Create(createBookRequest)
+	createBookResponse, inError := h.bookService.Create(ctx.Request.Context(), createBookRequest)
 	if inError != nil {
-		ctx.AbortWithStatusJSON(inError.Code, inError)
+		middlewares.AbortWithProblem(ctx, inError)
 		return
 	}
 	ctx.JSON(http.StatusOK, createBookResponse)
 }
 
 func (h *bookHandler) UpdateBook(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
 	var updateBookRequest models.CreateOrUpdateBookRequest
 	if err := ctx.ShouldBindJSON(&updateBookRequest); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	version, present, err := ifMatchVersion(ctx)
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, err)
+		return
+	}
+	if !present {
+		middlewares.AbortWithProblem(ctx, apperrors.PreconditionRequired("if_match_required", "If-Match header is required, take the ETag from GET /books/:id"))
 		return
 	}
-	inError := h.bookService.Update(updateBookRequest)
+	newVersion, inError := h.bookService.Update(ctx.Request.Context(), bookID, updateBookRequest, version)
 	if inError != nil {
-		ctx.AbortWithStatusJSON(inError.Code, inError)
+		if current, ok := apperrors.From(inError).Details.(models.Book); ok {
+			ctx.Header("ETag", versionETag(current.Version))
+		}
+		middlewares.AbortWithProblem(ctx, inError)
 		return
 	}
+	ctx.Header("ETag", versionETag(newVersion))
 	ctx.Status(http.StatusOK)
 }
 
@@ -66,14 +116,26 @@ func (h *bookHandler) FindBookById(ctx *gin.Context) {
 	var err error
 	bookID, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "book id not valid"})
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
+	book, errResponse := h.bookService.FindById(ctx.Request.Context(), bookID)
+	if errResponse != nil {
+		middlewares.AbortWithProblem(ctx, errResponse)
 		return
 	}
-	book, errResponse := h.bookService.FindById(bookID)
+	ctx.Header("ETag", versionETag(book.Version))
+	ctx.JSON(http.StatusOK, book)
+}
+
+// FindBookByISBN ищет книгу по ISBN-10, ISBN-13 или строке со сканера штрихкодов EAN-13
+func (h *bookHandler) FindBookByISBN(ctx *gin.Context) {
+	book, errResponse := h.bookService.FindByISBN(ctx.Request.Context(), ctx.Param("isbn"))
 	if errResponse != nil {
-		ctx.AbortWithStatusJSON(errResponse.Code, err)
+		middlewares.AbortWithProblem(ctx, errResponse)
 		return
 	}
+	ctx.Header("ETag", versionETag(book.Version))
 	ctx.JSON(http.StatusOK, book)
 }
 
@@ -85,7 +147,7 @@ func (h *bookHandler) FindBookByParameters(ctx *gin.Context) {
 	if ctx.Query("yearOfWriting") != "" {
 		yearOfWriting, err := time.Parse(time.DateOnly, ctx.Query("yearOfWriting"))
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "cannot convert date yearOfWriting"})
+			middlewares.AbortWithProblem(ctx, invalidDate("yearOfWriting", "YYYY-MM-DD"))
 			return
 		}
 		yearOfWritingPtr = &yearOfWriting
@@ -93,38 +155,164 @@ func (h *bookHandler) FindBookByParameters(ctx *gin.Context) {
 	if ctx.Query("yearOfBirth") != "" {
 		yearOfBirth, err := time.Parse(time.DateOnly, ctx.Query("yearOfBirth"))
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "cannot convert date yearOfBirth"})
+			middlewares.AbortWithProblem(ctx, invalidDate("yearOfBirth", "YYYY-MM-DD"))
 			return
 		}
 		yearOfBirthPtr = &yearOfBirth
 	}
-	books, inError := h.bookService.FindByParameters(title, author, yearOfWritingPtr, yearOfBirthPtr)
+	books, inError := h.bookService.FindByParameters(ctx.Request.Context(), title, author, yearOfWritingPtr, yearOfBirthPtr)
 	if inError != nil {
-		ctx.AbortWithStatusJSON(inError.Code, inError)
+		middlewares.AbortWithProblem(ctx, inError)
 		return
 	}
 	ctx.JSON(http.StatusOK, books)
 }
 
 func (h *bookHandler) GetAllBooks(ctx *gin.Context) {
-	books, err := h.bookService.GetAll()
+	var listBooksRequest models.ListBooksRequest
+	if err := ctx.ShouldBindQuery(&listBooksRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	books, err := h.bookService.List(ctx.Request.Context(), listBooksRequest)
 	if err != nil {
-		ctx.AbortWithStatusJSON(err.Code, err)
+		middlewares.AbortWithProblem(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, books)
 }
 
+// ExportBooks отдаёт весь каталог по мере чтения из БД: ответ не буферизуется, строки уходят
+// чанками, а обрыв соединения отменяет контекст запроса и вместе с ним курсор в БД
+func (h *bookHandler) ExportBooks(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", "ndjson")
+	var writeBook func(book models.Book) error
+	var flush func() error
+	switch format {
+	case "ndjson":
+		ctx.Header("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(ctx.Writer)
+		writeBook = func(book models.Book) error { return encoder.Encode(book) }
+		flush = func() error { return nil }
+	case "csv":
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Header("Content-Disposition", `attachment; filename="books.csv"`)
+		writer := csv.NewWriter(ctx.Writer)
+		// заголовок пишется вместе с первой строкой, чтобы до неё ещё можно было ответить ошибкой
+		headerWritten := false
+		writeHeader := func() error {
+			if headerWritten {
+				return nil
+			}
+			headerWritten = true
+			return writer.Write(exportCSVHeader)
+		}
+		writeBook = func(book models.Book) error {
+			if err := writeHeader(); err != nil {
+				return err
+			}
+			isbn := ""
+			if book.ISBN != nil {
+				isbn = *book.ISBN
+			}
+			return writer.Write([]string{
+				book.ID.String(),
+				book.Title,
+				isbn,
+				book.DateOfWriting.Format(time.DateOnly),
+				exportContributors(book.Contributors),
+				strconv.Itoa(book.OnHand),
+				strconv.Itoa(book.Reserved),
+				strconv.Itoa(book.Available),
+			})
+		}
+		flush = func() error {
+			if err := writeHeader(); err != nil {
+				return err
+			}
+			writer.Flush()
+			return writer.Error()
+		}
+	default:
+		middlewares.AbortWithProblem(ctx, apperrors.Validation("unsupported_format", "format must be ndjson or csv").WithField("format", "must be one of: ndjson, csv"))
+		return
+	}
+
+	controller := http.NewResponseController(ctx.Writer)
+	_ = controller.SetWriteDeadline(time.Now().Add(exportWriteDeadline))
+	rows := 0
+	inError := h.bookService.Export(ctx.Request.Context(), func(book models.Book) error {
+		if err := writeBook(book); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+			_ = controller.SetWriteDeadline(time.Now().Add(exportWriteDeadline))
+		}
+		return ctx.Request.Context().Err()
+	})
+	if inError != nil {
+		if !ctx.Writer.Written() {
+			middlewares.AbortWithProblem(ctx, inError)
+			return
+		}
+		// заголовки и часть строк уже отправлены: штатное завершение дало бы клиенту корректный, но усечённый ответ.
+		// http.ErrAbortHandler сбрасывает соединение, и клиент получит ошибку чтения вместо неполной выгрузки
+		_ = ctx.Error(inError)
+		panic(http.ErrAbortHandler)
+	}
+	if err := flush(); err == nil {
+		ctx.Status(http.StatusOK)
+		ctx.Writer.WriteHeaderNow()
+		ctx.Writer.Flush()
+	}
+}
+
 func (h *bookHandler) ChangeQuantity(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
 	var changeQuantity models.ChangeBookQuantityRequest
 	if err := ctx.ShouldBindJSON(&changeQuantity); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
 		return
 	}
-	changeQuantityResponse, inError := h.bookService.ChangeQuantity(changeQuantity)
+	changeQuantityResponse, inError := h.bookService.ChangeQuantity(ctx.Request.Context(), bookID, changeQuantity, actorFromContext(ctx))
 	if inError != nil {
-		ctx.AbortWithStatusJSON(inError.Code, inError)
+		middlewares.AbortWithProblem(ctx, inError)
 		return
 	}
 	ctx.JSON(http.StatusOK, changeQuantityResponse)
 }
+
+func (h *bookHandler) DeleteBook(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
+	if inError := h.bookService.Delete(ctx.Request.Context(), bookID, actorFromContext(ctx)); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// exportContributors перечисляет участников в одной ячейке CSV: "Толстой Лев Николаевич; Иванов Пётр (translator)"
+func exportContributors(contributors []models.Contributor) string {
+	names := make([]string, 0, len(contributors))
+	for _, contributor := range contributors {
+		name := contributor.Author.FullName
+		if contributor.Role != models.ContributorAuthor {
+			name += " (" + contributor.Role + ")"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, "; ")
+}
@@ -0,0 +1,527 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin_main/internal/models"
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+	"gin_main/pkg/idempotency"
+	"gin_main/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type fakeBookService struct {
+	calls        []string
+	lastID       uuid.UUID
+	lastTitle    string
+	lastQuantity int
+	lastList     models.ListBooksRequest
+	lastISBN     string
+	lastVersion  int
+	exportBooks  []models.Book
+	exportErr    error // ошибка чтения после выдачи всех exportBooks
+	err          error
+}
+
+func (f *fakeBookService) Create(ctx context.Context, book models.CreateOrUpdateBookRequest) (models.CreateBookResponse, error) {
+	f.calls = append(f.calls, "Create")
+	f.lastTitle = book.Title
+	return models.CreateBookResponse{ID: uuid.New()}, f.err
+}
+
+func (f *fakeBookService) Update(ctx context.Context, id uuid.UUID, book models.CreateOrUpdateBookRequest, version int) (int, error) {
+	f.calls = append(f.calls, "Update")
+	f.lastID = id
+	f.lastTitle = book.Title
+	f.lastVersion = version
+	return version + 1, f.err
+}
+
+func (f *fakeBookService) FindById(ctx context.Context, id uuid.UUID) (models.Book, error) {
+	f.calls = append(f.calls, "FindById")
+	f.lastID = id
+	return models.Book{ID: id, Version: 2}, f.err
+}
+
+func (f *fakeBookService) FindByISBN(ctx context.Context, raw string) (models.Book, error) {
+	f.calls = append(f.calls, "FindByISBN")
+	f.lastISBN = raw
+	return models.Book{}, f.err
+}
+
+func (f *fakeBookService) FindByParameters(ctx context.Context, title, author string, yearOfWriting, yearOfBirth *time.Time) ([]models.Book, error) {
+	f.calls = append(f.calls, "FindByParameters")
+	f.lastTitle = title
+	return []models.Book{}, f.err
+}
+
+func (f *fakeBookService) List(ctx context.Context, request models.ListBooksRequest) (pagination.Page[models.Book], error) {
+	f.calls = append(f.calls, "List")
+	f.lastList = request
+	return pagination.Page[models.Book]{Items: []models.Book{}}, f.err
+}
+
+func (f *fakeBookService) Export(ctx context.Context, visit func(models.Book) error) error {
+	f.calls = append(f.calls, "Export")
+	if f.err != nil {
+		return f.err
+	}
+	for _, book := range f.exportBooks {
+		if err := visit(book); err != nil {
+			return apperrors.Internal(err)
+		}
+	}
+	return f.exportErr
+}
+
+func (f *fakeBookService) ChangeQuantity(ctx context.Context, id uuid.UUID, book models.ChangeBookQuantityRequest, actor models.Actor) (models.ChangeBookQuantityResponse, error) {
+	f.calls = append(f.calls, "ChangeQuantity")
+	f.lastID = id
+	f.lastQuantity = book.Quantity
+	return models.ChangeBookQuantityResponse{LocationQuantity: book.Quantity, Quantity: book.Quantity}, f.err
+}
+
+func (f *fakeBookService) Delete(ctx context.Context, id uuid.UUID, actor models.Actor) error {
+	f.calls = append(f.calls, "Delete")
+	f.lastID = id
+	return f.err
+}
+
+// fakeAuthenticator принимает в качестве токена название роли
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) Authenticate(ctx context.Context, token string) (*middlewares.Identity, error) {
+	switch token {
+	case models.RoleViewer, models.RoleClerk, models.RoleManager:
+		return &middlewares.Identity{UserID: uuid.NewSHA1(uuid.NameSpaceOID, []byte(token)), Login: token, Role: token}, nil
+	default:
+		return nil, errors.New("invalid token")
+	}
+}
+
+// memoryIdempotencyStore хранит ключи идемпотентности в памяти теста
+type memoryIdempotencyStore struct {
+	records map[string]*idempotency.Record
+}
+
+func (s *memoryIdempotencyStore) Begin(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (*idempotency.Record, bool, error) {
+	if record, exists := s.records[scope+key]; exists {
+		return record, false, nil
+	}
+	s.records[scope+key] = &idempotency.Record{RequestHash: requestHash}
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	record := s.records[scope+key]
+	record.Completed, record.StatusCode, record.ContentType, record.Body = true, statusCode, contentType, body
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, scope, key string) error {
+	delete(s.records, scope+key)
+	return nil
+}
+
+func newBookTestEngine(service *fakeBookService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	idempotency := middlewares.IdempotencyMiddleware(&memoryIdempotencyStore{records: map[string]*idempotency.Record{}}, time.Hour, MaxBookBodySize)
+	router.RegisterProtectedEndpoints(engine, fakeAuthenticator{}, NewBookHandler(service, idempotency))
+	return engine
+}
+
+// serveBookRequest выполняет запрос от имени роли; headers - пары "имя", "значение" дополнительных заголовков
+func serveBookRequest(engine *gin.Engine, method, path, body, role string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if role != "" {
+		req.Header.Set("Authorization", "Bearer "+role)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBookRoutes(t *testing.T) {
+	bookID := uuid.New()
+	bookBody := `{"year":"1869-01-01T00:00:00Z","title":"War and Peace","author":{"surname":"Tolstoy"}}`
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		role       string
+		wantStatus int
+		wantCall   string
+		wantID     uuid.UUID
+	}{
+		{"create", http.MethodPost, "/api/v1/books", bookBody, models.RoleClerk, http.StatusOK, "Create", uuid.Nil},
+		{"get all", http.MethodGet, "/api/v1/books", "", models.RoleViewer, http.StatusOK, "List", uuid.Nil},
+		{"search", http.MethodGet, "/api/v1/books/search?title=War", "", models.RoleViewer, http.StatusOK, "FindByParameters", uuid.Nil},
+		{"find by isbn", http.MethodGet, "/api/v1/books/isbn/9785170906307", "", models.RoleViewer, http.StatusOK, "FindByISBN", uuid.Nil},
+		{"find by id", http.MethodGet, "/api/v1/books/" + bookID.String(), "", models.RoleViewer, http.StatusOK, "FindById", bookID},
+		{"update", http.MethodPut, "/api/v1/books/" + bookID.String(), bookBody, models.RoleManager, http.StatusOK, "Update", bookID},
+		{"change quantity", http.MethodPatch, "/api/v1/books/" + bookID.String() + "/quantity", `{"locationId":"` + uuid.New().String() + `","quantity":-2,"reason":"sale"}`, models.RoleClerk, http.StatusOK, "ChangeQuantity", bookID},
+		{"delete", http.MethodDelete, "/api/v1/books/" + bookID.String(), "", models.RoleManager, http.StatusNoContent, "Delete", bookID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeBookService{}
+			rec := serveBookRequest(newBookTestEngine(service), tt.method, tt.path, tt.body, tt.role, "If-Match", `"1"`)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if len(service.calls) != 1 || service.calls[0] != tt.wantCall {
+				t.Fatalf("calls = %v, want [%s]", service.calls, tt.wantCall)
+			}
+			if service.lastID != tt.wantID {
+				t.Errorf("id = %s, want %s", service.lastID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestUpdateBookTakesIDFromPath(t *testing.T) {
+	service := &fakeBookService{}
+	bookID := uuid.New()
+	body := `{"bookId":"` + uuid.New().String() + `","year":"1869-01-01T00:00:00Z","title":"War and Peace","author":{}}`
+	rec := serveBookRequest(newBookTestEngine(service), http.MethodPut, "/api/v1/books/"+bookID.String(), body, models.RoleClerk, "If-Match", `"1"`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if service.lastID != bookID || service.lastTitle != "War and Peace" {
+		t.Errorf("got id %s title %q, want id %s", service.lastID, service.lastTitle, bookID)
+	}
+}
+
+func TestUpdateBookChecksIfMatch(t *testing.T) {
+	bookID := uuid.New()
+	path := "/api/v1/books/" + bookID.String()
+	body := `{"year":"1869-01-01T00:00:00Z","title":"War and Peace","author":{"surname":"Tolstoy"}}`
+
+	service := &fakeBookService{}
+	rec := serveBookRequest(newBookTestEngine(service), http.MethodPut, path, body, models.RoleClerk)
+	if rec.Code != http.StatusPreconditionRequired || len(service.calls) != 0 {
+		t.Errorf("without If-Match: status = %d, calls = %v", rec.Code, service.calls)
+	}
+
+	for _, malformed := range []string{"3", `"abc"`, `"1", "2"`} {
+		service = &fakeBookService{}
+		rec = serveBookRequest(newBookTestEngine(service), http.MethodPut, path, body, models.RoleClerk, "If-Match", malformed)
+		if rec.Code != http.StatusBadRequest || len(service.calls) != 0 {
+			t.Errorf("If-Match %s: status = %d, calls = %v", malformed, rec.Code, service.calls)
+		}
+	}
+
+	service = &fakeBookService{}
+	rec = serveBookRequest(newBookTestEngine(service), http.MethodPut, path, body, models.RoleClerk, "If-Match", `"3"`)
+	if rec.Code != http.StatusOK || service.lastVersion != 3 || rec.Header().Get("ETag") != `"4"` {
+		t.Errorf("current version: status = %d, version = %d, ETag = %s", rec.Code, service.lastVersion, rec.Header().Get("ETag"))
+	}
+
+	service = &fakeBookService{}
+	rec = serveBookRequest(newBookTestEngine(service), http.MethodPut, path, body, models.RoleClerk, "If-Match", "*")
+	if rec.Code != http.StatusOK || service.lastVersion != 0 {
+		t.Errorf("any version: status = %d, version = %d", rec.Code, service.lastVersion)
+	}
+
+	current := models.Book{ID: bookID, Title: "Anna Karenina", Version: 5}
+	service = &fakeBookService{err: apperrors.PreconditionFailed("version_mismatch", "modified").WithDetails(current)}
+	rec = serveBookRequest(newBookTestEngine(service), http.MethodPut, path, body, models.RoleClerk, "If-Match", `"3"`)
+	if rec.Code != http.StatusPreconditionFailed || rec.Header().Get("ETag") != `"5"` {
+		t.Fatalf("stale version: status = %d, ETag = %s", rec.Code, rec.Header().Get("ETag"))
+	}
+	var response struct {
+		Details models.Book `json:"details"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || response.Details.Title != "Anna Karenina" {
+		t.Errorf("stale version body = %s", rec.Body.String())
+	}
+}
+
+func TestFindBookByIdSetsETag(t *testing.T) {
+	rec := serveBookRequest(newBookTestEngine(&fakeBookService{}), http.MethodGet, "/api/v1/books/"+uuid.New().String(), "", models.RoleViewer)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Errorf("status = %d, ETag = %s", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestCreateBookValidatesISBN(t *testing.T) {
+	body := func(isbn string) string {
+		return `{"year":"1869-01-01T00:00:00Z","title":"War and Peace","isbn":"` + isbn + `","author":{"surname":"Tolstoy"}}`
+	}
+	for _, valid := range []string{"978-5-17-090630-7", "0-306-40615-2", "080442957X", ""} {
+		service := &fakeBookService{}
+		rec := serveBookRequest(newBookTestEngine(service), http.MethodPost, "/api/v1/books", body(valid), models.RoleClerk)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%q: status = %d, body = %s", valid, rec.Code, rec.Body.String())
+		}
+	}
+	for _, invalid := range []string{"978-5-17-090630-8", "0-306-40615-3", "12345", "978030640615712", "9780306406157X1"} {
+		service := &fakeBookService{}
+		rec := serveBookRequest(newBookTestEngine(service), http.MethodPost, "/api/v1/books", body(invalid), models.RoleClerk)
+		if rec.Code != http.StatusBadRequest || len(service.calls) != 0 {
+			t.Errorf("%q: status = %d, calls = %v", invalid, rec.Code, service.calls)
+		}
+	}
+}
+
+func TestCreateBookValidatesContributors(t *testing.T) {
+	body := func(role string) string {
+		return `{"year":"1869-01-01T00:00:00Z","title":"War and Peace","contributors":[` +
+			`{"authorId":"` + uuid.New().String() + `"},{"author":{"surname":"Maude"},"role":"` + role + `"}]}`
+	}
+	service := &fakeBookService{}
+	rec := serveBookRequest(newBookTestEngine(service), http.MethodPost, "/api/v1/books", body("translator"), models.RoleClerk)
+	if rec.Code != http.StatusOK {
+		t.Errorf("translator: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	service = &fakeBookService{}
+	rec = serveBookRequest(newBookTestEngine(service), http.MethodPost, "/api/v1/books", body("narrator"), models.RoleClerk)
+	if rec.Code != http.StatusBadRequest || len(service.calls) != 0 {
+		t.Errorf("unknown role: status = %d, calls = %v", rec.Code, service.calls)
+	}
+}
+
+func TestFindBookByISBNPassesScannedCode(t *testing.T) {
+	service := &fakeBookService{}
+	rec := serveBookRequest(newBookTestEngine(service), http.MethodGet, "/api/v1/books/isbn/978-0-306-40615-7", "", models.RoleViewer)
+
+	if rec.Code != http.StatusOK || service.lastISBN != "978-0-306-40615-7" {
+		t.Errorf("status = %d, isbn = %q", rec.Code, service.lastISBN)
+	}
+}
+
+func TestListBooksBindsQuery(t *testing.T) {
+	service := &fakeBookService{}
+	authorID := uuid.New().String()
+	path := "/api/v1/books?after=abc&limit=10&sort=title,-dateOfWriting&authorId=" + authorID + "&minQuantity=1&writtenFrom=1860-01-01"
+	rec := serveBookRequest(newBookTestEngine(service), http.MethodGet, path, "", models.RoleViewer)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	got := service.lastList
+	if got.After != "abc" || got.Limit != 10 || got.Sort != "title,-dateOfWriting" || got.AuthorID != authorID ||
+		got.MinQuantity == nil || *got.MinQuantity != 1 || got.MaxQuantity != nil || got.WrittenFrom != "1860-01-01" {
+		t.Errorf("request = %+v", got)
+	}
+	if !strings.Contains(rec.Body.String(), `"items":[]`) {
+		t.Errorf("body = %s, want items array", rec.Body.String())
+	}
+
+	for _, query := range []string{"authorId=1", "minQuantity=-1", "writtenTo=yesterday", "limit=0x"} {
+		service := &fakeBookService{}
+		rec := serveBookRequest(newBookTestEngine(service), http.MethodGet, "/api/v1/books?"+query, "", models.RoleViewer)
+		if rec.Code != http.StatusBadRequest || len(service.calls) != 0 {
+			t.Errorf("%s: status = %d, calls = %v", query, rec.Code, service.calls)
+		}
+	}
+}
+
+func TestExportBooks(t *testing.T) {
+	isbn := "9785170906307"
+	books := []models.Book{
+		{ID: uuid.New(), Title: "War and Peace", ISBN: &isbn, DateOfWriting: time.Date(1869, 1, 1, 0, 0, 0, 0, time.UTC), Contributors: []models.Contributor{
+			{Author: models.Author{FullName: "Tolstoy Lev"}, Role: models.ContributorAuthor},
+			{Author: models.Author{FullName: "Maude Louise"}, Role: models.ContributorTranslator},
+		}, OnHand: 3, Available: 3},
+		{ID: uuid.New(), Title: "Anna, Karenina", OnHand: 1, Reserved: 1},
+	}
+
+	service := &fakeBookService{exportBooks: books}
+	rec := serveBookRequest(newBookTestEngine(service), http.MethodGet, "/api/v1/books/export", "", models.RoleViewer)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("ndjson: status = %d, content type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ndjson: got %d lines: %s", len(lines), rec.Body.String())
+	}
+	var first models.Book
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.ID != books[0].ID {
+		t.Errorf("ndjson: first line = %s, err = %v", lines[0], err)
+	}
+
+	rec = serveBookRequest(newBookTestEngine(&fakeBookService{exportBooks: books}), http.MethodGet, "/api/v1/books/export?format=csv", "", models.RoleViewer)
+	wantCSV := "bookId,title,isbn,year,contributors,onHand,reserved,available\n" +
+		books[0].ID.String() + ",War and Peace,9785170906307,1869-01-01,Tolstoy Lev; Maude Louise (translator),3,0,3\n" +
+		books[1].ID.String() + `,"Anna, Karenina",,0001-01-01,,1,1,0` + "\n"
+	if rec.Code != http.StatusOK || rec.Body.String() != wantCSV {
+		t.Errorf("csv: status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+
+	rec = serveBookRequest(newBookTestEngine(&fakeBookService{}), http.MethodGet, "/api/v1/books/export?format=csv", "", models.RoleViewer)
+	if rec.Body.String() != "bookId,title,isbn,year,contributors,onHand,reserved,available\n" {
+		t.Errorf("empty csv: body = %q", rec.Body.String())
+	}
+
+	rec = serveBookRequest(newBookTestEngine(&fakeBookService{}), http.MethodGet, "/api/v1/books/export?format=xml", "", models.RoleViewer)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: status = %d", rec.Code)
+	}
+
+	failing := &fakeBookService{err: apperrors.Internal(errors.New("connection refused"))}
+	rec = serveBookRequest(newBookTestEngine(failing), http.MethodGet, "/api/v1/books/export", "", models.RoleViewer)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("failure before first row: status = %d", rec.Code)
+	}
+}
+
+func TestExportBooksResetsConnectionOnFailureAfterFlush(t *testing.T) {
+	books := make([]models.Book, exportFlushEvery+5)
+	for i := range books {
+		books[i] = models.Book{ID: uuid.New(), Title: "Book"}
+	}
+	service := &fakeBookService{exportBooks: books, exportErr: apperrors.Internal(errors.New("cursor closed"))}
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middlewares.RecoveryMiddleware())
+	router.RegisterProtectedEndpoints(engine, fakeAuthenticator{}, NewBookHandler(service, func(ctx *gin.Context) { ctx.Next() }))
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/books/export", nil)
+	req.Header.Set("Authorization", "Bearer "+models.RoleViewer)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 sent before the failure", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("read error = %v, want unexpected EOF from the reset connection", err)
+	}
+	if lines := strings.Count(string(body), "\n"); lines < exportFlushEvery {
+		t.Errorf("received %d lines before the reset, want at least %d", lines, exportFlushEvery)
+	}
+}
+
+func TestBookRoutesRejectInvalidID(t *testing.T) {
+	paths := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/api/v1/books/not-a-uuid", ""},
+		{http.MethodPut, "/api/v1/books/not-a-uuid", `{}`},
+		{http.MethodPatch, "/api/v1/books/not-a-uuid/quantity", `{"quantity":1}`},
+	}
+	for _, p := range paths {
+		service := &fakeBookService{}
+		rec := serveBookRequest(newBookTestEngine(service), p.method, p.path, p.body, models.RoleClerk)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: status = %d, want %d", p.method, p.path, rec.Code, http.StatusBadRequest)
+		}
+		if len(service.calls) != 0 {
+			t.Errorf("%s %s: service called %v", p.method, p.path, service.calls)
+		}
+	}
+}
+
+func TestBookRoutesPropagateServiceError(t *testing.T) {
+	service := &fakeBookService{err: apperrors.NotFound("book_not_found", "book not found")}
+	rec := serveBookRequest(newBookTestEngine(service), http.MethodGet, "/api/v1/books/"+uuid.New().String(), "", models.RoleViewer)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != apperrors.ProblemContentType {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+	var problem apperrors.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("cannot decode body: %v", err)
+	}
+	if problem.Code != "book_not_found" || problem.Detail != "book not found" || problem.Status != http.StatusNotFound {
+		t.Errorf("problem = %+v", problem)
+	}
+}
+
+func TestBookRoutesEnforceRoles(t *testing.T) {
+	bookID := uuid.New().String()
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		role       string
+		wantStatus int
+	}{
+		{"no token", http.MethodGet, "/api/v1/books", "", "", http.StatusUnauthorized},
+		{"bad token", http.MethodGet, "/api/v1/books", "", "intruder", http.StatusUnauthorized},
+		{"viewer cannot create", http.MethodPost, "/api/v1/books", `{}`, models.RoleViewer, http.StatusForbidden},
+		{"viewer cannot change quantity", http.MethodPatch, "/api/v1/books/" + bookID + "/quantity", `{"quantity":1}`, models.RoleViewer, http.StatusForbidden},
+		{"manager cannot change quantity", http.MethodPatch, "/api/v1/books/" + bookID + "/quantity", `{"quantity":1}`, models.RoleManager, http.StatusForbidden},
+		{"clerk cannot delete", http.MethodDelete, "/api/v1/books/" + bookID, "", models.RoleClerk, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeBookService{}
+			rec := serveBookRequest(newBookTestEngine(service), tt.method, tt.path, tt.body, tt.role, "If-Match", `"1"`)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if len(service.calls) != 0 {
+				t.Errorf("service called %v", service.calls)
+			}
+		})
+	}
+}
+
+func TestChangeQuantityIdempotencyKey(t *testing.T) {
+	service := &fakeBookService{}
+	engine := newBookTestEngine(service)
+	path := "/api/v1/books/" + uuid.New().String() + "/quantity"
+	body := `{"locationId":"` + uuid.New().String() + `","quantity":5,"reason":"receipt"}`
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+models.RoleClerk)
+		req.Header.Set(middlewares.IdempotencyKeyHeader, "retry-1")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send(body)
+	replay := send(body)
+	if first.Code != http.StatusOK || replay.Code != http.StatusOK {
+		t.Fatalf("status = %d then %d, want 200 twice", first.Code, replay.Code)
+	}
+	if replay.Body.String() != first.Body.String() || replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replay returned %q, want stored %q", replay.Body.String(), first.Body.String())
+	}
+	if len(service.calls) != 1 {
+		t.Errorf("service called %d times, want once", len(service.calls))
+	}
+
+	conflict := send(strings.Replace(body, `"quantity":5`, `"quantity":7`, 1))
+	if conflict.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d for different payload, want %d", conflict.Code, http.StatusUnprocessableEntity)
+	}
+}
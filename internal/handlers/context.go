@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/pkg/httpserver/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actorFromContext собирает сведения об инициаторе запроса для журнала движения и отметок об удалении
+func actorFromContext(ctx *gin.Context) models.Actor {
+	actor := models.Actor{CorrelationID: middlewares.GetRequestID(ctx)}
+	if identity, ok := middlewares.GetIdentity(ctx); ok {
+		actor.UserID = identity.UserID
+	}
+	return actor
+}
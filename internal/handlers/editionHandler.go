@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EditionHandlerInterface interface {
+	router.HandlerInterface
+	CreateEdition(ctx *gin.Context)
+	UpdateEdition(ctx *gin.Context)
+	GetEditions(ctx *gin.Context)
+	DeleteEdition(ctx *gin.Context)
+}
+
+type editionHandler struct {
+	editionService services.EditionServiceInterface
+}
+
+func NewEditionHandler(editionService services.EditionServiceInterface) EditionHandlerInterface {
+	return &editionHandler{editionService: editionService}
+}
+
+func (h *editionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	writers := middlewares.RequireRole(models.RoleClerk, models.RoleManager)
+
+	router.GET("/books/:id/editions", h.GetEditions)
+	router.POST("/books/:id/editions", writers, h.CreateEdition)
+	router.PUT("/books/:id/editions/:editionId", writers, h.UpdateEdition)
+	router.DELETE("/books/:id/editions/:editionId", middlewares.RequireRole(models.RoleManager), h.DeleteEdition)
+}
+
+func (h *editionHandler) CreateEdition(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
+	var createEditionRequest models.CreateOrUpdateEditionRequest
+	if err := ctx.ShouldBindJSON(&createEditionRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	createEditionResponse, inError := h.editionService.Create(ctx.Request.Context(), bookID, createEditionRequest)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, createEditionResponse)
+}
+
+func (h *editionHandler) UpdateEdition(ctx *gin.Context) {
+	bookID, editionID, ok := editionPath(ctx)
+	if !ok {
+		return
+	}
+	var updateEditionRequest models.CreateOrUpdateEditionRequest
+	if err := ctx.ShouldBindJSON(&updateEditionRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	if inError := h.editionService.Update(ctx.Request.Context(), bookID, editionID, updateEditionRequest); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+func (h *editionHandler) GetEditions(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
+	editions, inError := h.editionService.GetByBook(ctx.Request.Context(), bookID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, editions)
+}
+
+func (h *editionHandler) DeleteEdition(ctx *gin.Context) {
+	bookID, editionID, ok := editionPath(ctx)
+	if !ok {
+		return
+	}
+	if inError := h.editionService.Delete(ctx.Request.Context(), bookID, editionID); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// editionPath разбирает id книги и издания из пути; при ошибке ответ уже отправлен
+func editionPath(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	editionID, err := uuid.Parse(ctx.Param("editionId"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("edition"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return bookID, editionID, true
+}
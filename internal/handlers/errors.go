@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"strconv"
+
+	"gin_main/pkg/apperrors"
+)
+
+// invalidID - ошибка разбора идентификатора из пути запроса; entity - название сущности: "book", "purchase order"
+func invalidID(entity string) *apperrors.Error {
+	return apperrors.Validation("invalid_id", entity+" id not valid").WithField("id", "must be a UUID")
+}
+
+func invalidDate(field, layout string) *apperrors.Error {
+	return apperrors.Validation("invalid_date", "cannot convert date "+field).WithField(field, "must be a date in "+layout+" format")
+}
+
+func invalidLimit(max int) *apperrors.Error {
+	return apperrors.Validation("invalid_limit", "limit must be between 1 and "+strconv.Itoa(max)).
+		WithField("limit", "must be between 1 and "+strconv.Itoa(max))
+}
+
+func invalidOffset() *apperrors.Error {
+	return apperrors.Validation("invalid_offset", "offset not valid").WithField("offset", "must be a non-negative integer")
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"gin_main/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidIfMatch = apperrors.Validation("invalid_if_match", "If-Match must contain a single ETag received from GET").
+	WithField("If-Match", "must be a quoted version number, for example \"3\"")
+
+// versionETag превращает номер версии записи в значение заголовка ETag
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion разбирает заголовок If-Match; "*" означает любую версию и возвращается как 0.
+// Слабый ETag (W/"3") принимается так же, как сильный: его могут выдать прокси, сжимающие ответ
+func ifMatchVersion(ctx *gin.Context) (version int, present bool, err error) {
+	value := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if value == "" {
+		return 0, false, nil
+	}
+	if value == "*" {
+		return 0, true, nil
+	}
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, true, errInvalidIfMatch
+	}
+	version, err = strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version <= 0 {
+		return 0, true, errInvalidIfMatch
+	}
+	return version, true, nil
+}
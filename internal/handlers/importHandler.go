@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+	"gin_main/pkg/tabular"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize ограничивает загружаемый каталог, XLSX целиком читается в память
+const maxImportFileSize = 32 << 20
+
+type ImportHandlerInterface interface {
+	router.HandlerInterface
+	ImportBooks(ctx *gin.Context)
+}
+
+type importHandler struct {
+	importService services.ImportServiceInterface
+	timeout       time.Duration
+}
+
+// timeout - срок импорта (timeouts.import), на него продлеваются сроки чтения и записи соединения
+func NewImportHandler(importService services.ImportServiceInterface, timeout time.Duration) ImportHandlerInterface {
+	return &importHandler{importService: importService, timeout: timeout}
+}
+
+func (h *importHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/books/import", middlewares.RequireRole(models.RoleManager), h.ImportBooks)
+}
+
+// ImportBooks принимает файл полем file формы multipart/form-data или телом запроса;
+// формат берётся из параметра format, иначе из расширения загруженного файла
+func (h *importHandler) ImportBooks(ctx *gin.Context) {
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.Validation("invalid_dry_run", "dryRun must be true or false").WithField("dryRun", "must be true or false"))
+		return
+	}
+	// read_timeout и write_timeout сервера оборвали бы загрузку большого каталога раньше срока импорта
+	controller := http.NewResponseController(ctx.Writer)
+	deadline := time.Now().Add(h.timeout)
+	_ = controller.SetReadDeadline(deadline)
+	_ = controller.SetWriteDeadline(deadline)
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize)
+
+	format := ctx.Query("format")
+	var file io.Reader = ctx.Request.Body
+	if fileHeader, err := ctx.FormFile("file"); err == nil {
+		opened, err := fileHeader.Open()
+		if err != nil {
+			middlewares.AbortWithProblem(ctx, apperrors.Validation("unreadable_body", "cannot read uploaded file").WithField("file", "is required"))
+			return
+		}
+		defer opened.Close()
+		file = opened
+		if format == "" {
+			format = tabular.FormatFromName(fileHeader.Filename)
+		}
+	}
+	if format == "" {
+		middlewares.AbortWithProblem(ctx, apperrors.Validation("unsupported_format", "format must be csv or xlsx").WithField("file", "must be a .csv or .xlsx file"))
+		return
+	}
+
+	report, inError := h.importService.Import(ctx.Request.Context(), file, format, dryRun)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin_main/internal/models"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeImportService struct {
+	body string
+}
+
+func (f *fakeImportService) Import(ctx context.Context, file io.Reader, format string, dryRun bool) (models.ImportReport, error) {
+	data, err := io.ReadAll(file)
+	f.body = string(data)
+	return models.ImportReport{DryRun: dryRun}, err
+}
+
+// deadlineRecorder запоминает сроки, выставленные через http.ResponseController
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func (r *deadlineRecorder) SetReadDeadline(deadline time.Time) error {
+	r.readDeadline = deadline
+	return nil
+}
+
+func (r *deadlineRecorder) SetWriteDeadline(deadline time.Time) error {
+	r.writeDeadline = deadline
+	return nil
+}
+
+func TestImportBooksExtendsConnectionDeadlines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &fakeImportService{}
+	engine := gin.New()
+	router.RegisterProtectedEndpoints(engine, fakeAuthenticator{}, NewImportHandler(service, 10*time.Minute))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/books/import?format=csv", strings.NewReader("title\nWar and Peace\n"))
+	req.Header.Set("Authorization", "Bearer "+models.RoleManager)
+	rec := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+	start := time.Now()
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if service.body != "title\nWar and Peace\n" {
+		t.Errorf("service read %q, want the request body", service.body)
+	}
+	for name, deadline := range map[string]time.Time{"read": rec.readDeadline, "write": rec.writeDeadline} {
+		if deadline.Before(start.Add(10*time.Minute)) || deadline.After(time.Now().Add(10*time.Minute)) {
+			t.Errorf("%s deadline = %v, want import timeout from the start of the request", name, deadline)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrderHandlerInterface interface {
+	router.HandlerInterface
+	CreateOrder(ctx *gin.Context)
+	GetAllOrders(ctx *gin.Context)
+	FindOrderById(ctx *gin.Context)
+	ChangeOrderStatus(ctx *gin.Context)
+}
+
+type orderHandler struct {
+	orderService services.OrderServiceInterface
+}
+
+func NewOrderHandler(orderService services.OrderServiceInterface) OrderHandlerInterface {
+	return &orderHandler{orderService: orderService}
+}
+
+func (h *orderHandler) RegisterRoutes(router *gin.RouterGroup) {
+	orders := router.Group("/orders", middlewares.RequireRole(models.RoleClerk, models.RoleManager))
+	orders.POST("", h.CreateOrder)
+	orders.GET("", h.GetAllOrders)
+	orders.GET("/:id", h.FindOrderById)
+	orders.PATCH("/:id/status", h.ChangeOrderStatus)
+}
+
+func (h *orderHandler) CreateOrder(ctx *gin.Context) {
+	var createOrderRequest models.CreateOrderRequest
+	if err := ctx.ShouldBindJSON(&createOrderRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	order, inError := h.orderService.Create(ctx.Request.Context(), createOrderRequest, actorFromContext(ctx))
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusCreated, order)
+}
+
+func (h *orderHandler) GetAllOrders(ctx *gin.Context) {
+	orders, inError := h.orderService.GetAll(ctx.Request.Context(), ctx.Query("status"))
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, orders)
+}
+
+func (h *orderHandler) FindOrderById(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("order"))
+		return
+	}
+	order, inError := h.orderService.FindById(ctx.Request.Context(), orderID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (h *orderHandler) ChangeOrderStatus(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("order"))
+		return
+	}
+	var changeStatusRequest models.ChangeOrderStatusRequest
+	if err := ctx.ShouldBindJSON(&changeStatusRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	order, inError := h.orderService.ChangeStatus(ctx.Request.Context(), orderID, changeStatusRequest.Status, actorFromContext(ctx))
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
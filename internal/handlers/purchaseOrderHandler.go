@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+	"time"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PurchaseOrderHandlerInterface interface {
+	router.HandlerInterface
+	CreateSupplier(ctx *gin.Context)
+	GetAllSuppliers(ctx *gin.Context)
+	FindSupplierById(ctx *gin.Context)
+	CreatePurchaseOrder(ctx *gin.Context)
+	GetAllPurchaseOrders(ctx *gin.Context)
+	FindPurchaseOrderById(ctx *gin.Context)
+	GetPurchaseOrderReceipts(ctx *gin.Context)
+	ReceivePurchaseOrder(ctx *gin.Context)
+	ClosePurchaseOrder(ctx *gin.Context)
+	GetPendingReport(ctx *gin.Context)
+}
+
+type purchaseOrderHandler struct {
+	purchaseOrderService services.PurchaseOrderServiceInterface
+}
+
+func NewPurchaseOrderHandler(purchaseOrderService services.PurchaseOrderServiceInterface) PurchaseOrderHandlerInterface {
+	return &purchaseOrderHandler{purchaseOrderService: purchaseOrderService}
+}
+
+func (h *purchaseOrderHandler) RegisterRoutes(router *gin.RouterGroup) {
+	managers := middlewares.RequireRole(models.RoleManager)
+	receivers := middlewares.RequireRole(models.RoleClerk, models.RoleManager)
+
+	router.POST("/suppliers", managers, h.CreateSupplier)
+	router.GET("/suppliers", h.GetAllSuppliers)
+	router.GET("/suppliers/:id", h.FindSupplierById)
+
+	purchaseOrders := router.Group("/purchase-orders")
+	purchaseOrders.POST("", managers, h.CreatePurchaseOrder)
+	purchaseOrders.GET("", h.GetAllPurchaseOrders)
+	purchaseOrders.GET("/report", h.GetPendingReport)
+	purchaseOrders.GET("/:id", h.FindPurchaseOrderById)
+	purchaseOrders.GET("/:id/receipts", h.GetPurchaseOrderReceipts)
+	purchaseOrders.POST("/:id/receipts", receivers, h.ReceivePurchaseOrder)
+	purchaseOrders.POST("/:id/close", managers, h.ClosePurchaseOrder)
+}
+
+func (h *purchaseOrderHandler) CreateSupplier(ctx *gin.Context) {
+	var createSupplierRequest models.CreateSupplierRequest
+	if err := ctx.ShouldBindJSON(&createSupplierRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	supplier, inError := h.purchaseOrderService.CreateSupplier(ctx.Request.Context(), createSupplierRequest)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusCreated, supplier)
+}
+
+func (h *purchaseOrderHandler) GetAllSuppliers(ctx *gin.Context) {
+	suppliers, inError := h.purchaseOrderService.GetAllSuppliers(ctx.Request.Context())
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, suppliers)
+}
+
+func (h *purchaseOrderHandler) FindSupplierById(ctx *gin.Context) {
+	supplierID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("supplier"))
+		return
+	}
+	supplier, inError := h.purchaseOrderService.FindSupplierById(ctx.Request.Context(), supplierID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, supplier)
+}
+
+func (h *purchaseOrderHandler) CreatePurchaseOrder(ctx *gin.Context) {
+	var createPurchaseOrderRequest models.CreatePurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&createPurchaseOrderRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	order, inError := h.purchaseOrderService.Create(ctx.Request.Context(), createPurchaseOrderRequest, actorFromContext(ctx))
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusCreated, order)
+}
+
+func (h *purchaseOrderHandler) GetAllPurchaseOrders(ctx *gin.Context) {
+	var supplierID uuid.UUID
+	if ctx.Query("supplierId") != "" {
+		var err error
+		if supplierID, err = uuid.Parse(ctx.Query("supplierId")); err != nil {
+			middlewares.AbortWithProblem(ctx, invalidID("supplier"))
+			return
+		}
+	}
+	orders, inError := h.purchaseOrderService.GetAll(ctx.Request.Context(), ctx.Query("status"), supplierID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, orders)
+}
+
+func (h *purchaseOrderHandler) FindPurchaseOrderById(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("purchase order"))
+		return
+	}
+	order, inError := h.purchaseOrderService.FindById(ctx.Request.Context(), orderID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (h *purchaseOrderHandler) GetPurchaseOrderReceipts(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("purchase order"))
+		return
+	}
+	receipts, inError := h.purchaseOrderService.GetReceipts(ctx.Request.Context(), orderID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, receipts)
+}
+
+func (h *purchaseOrderHandler) ReceivePurchaseOrder(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("purchase order"))
+		return
+	}
+	var receiveRequest models.ReceivePurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&receiveRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	receipt, inError := h.purchaseOrderService.Receive(ctx.Request.Context(), orderID, receiveRequest, actorFromContext(ctx))
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusCreated, receipt)
+}
+
+func (h *purchaseOrderHandler) ClosePurchaseOrder(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("purchase order"))
+		return
+	}
+	order, inError := h.purchaseOrderService.Close(ctx.Request.Context(), orderID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (h *purchaseOrderHandler) GetPendingReport(ctx *gin.Context) {
+	asOf := time.Now()
+	if ctx.Query("asOf") != "" {
+		var err error
+		if asOf, err = time.Parse(time.DateOnly, ctx.Query("asOf")); err != nil {
+			middlewares.AbortWithProblem(ctx, invalidDate("asOf", "YYYY-MM-DD"))
+			return
+		}
+	}
+	report, inError := h.purchaseOrderService.PendingReport(ctx.Request.Context(), asOf)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
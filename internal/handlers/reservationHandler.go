@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReservationHandlerInterface interface {
+	router.HandlerInterface
+	CreateReservation(ctx *gin.Context)
+	FindReservationById(ctx *gin.Context)
+	ConfirmReservation(ctx *gin.Context)
+	CancelReservation(ctx *gin.Context)
+}
+
+type reservationHandler struct {
+	reservationService services.ReservationServiceInterface
+}
+
+func NewReservationHandler(reservationService services.ReservationServiceInterface) ReservationHandlerInterface {
+	return &reservationHandler{reservationService: reservationService}
+}
+
+func (h *reservationHandler) RegisterRoutes(router *gin.RouterGroup) {
+	reservations := router.Group("/reservations", middlewares.RequireRole(models.RoleClerk, models.RoleManager))
+	reservations.POST("", h.CreateReservation)
+	reservations.GET("/:id", h.FindReservationById)
+	reservations.POST("/:id/confirm", h.ConfirmReservation)
+	reservations.POST("/:id/cancel", h.CancelReservation)
+}
+
+func (h *reservationHandler) CreateReservation(ctx *gin.Context) {
+	var createReservationRequest models.CreateReservationRequest
+	if err := ctx.ShouldBindJSON(&createReservationRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	reservation, inError := h.reservationService.Create(ctx.Request.Context(), createReservationRequest, actorFromContext(ctx))
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusCreated, reservation)
+}
+
+func (h *reservationHandler) FindReservationById(ctx *gin.Context) {
+	reservationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("reservation"))
+		return
+	}
+	reservation, inError := h.reservationService.FindById(ctx.Request.Context(), reservationID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+func (h *reservationHandler) ConfirmReservation(ctx *gin.Context) {
+	reservationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("reservation"))
+		return
+	}
+	reservation, inError := h.reservationService.Confirm(ctx.Request.Context(), reservationID, actorFromContext(ctx))
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+func (h *reservationHandler) CancelReservation(ctx *gin.Context) {
+	reservationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("reservation"))
+		return
+	}
+	reservation, inError := h.reservationService.Cancel(ctx.Request.Context(), reservationID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, reservation)
+}
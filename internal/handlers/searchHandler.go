@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"gin_main/internal/services"
+	"net/http"
+	"strconv"
+
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type SearchHandlerInterface interface {
+	router.HandlerInterface
+	SearchBooks(ctx *gin.Context)
+	SearchAuthors(ctx *gin.Context)
+}
+
+type searchHandler struct {
+	searchService services.SearchServiceInterface
+}
+
+func NewSearchHandler(searchService services.SearchServiceInterface) SearchHandlerInterface {
+	return &searchHandler{searchService: searchService}
+}
+
+func (h *searchHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/search/books", h.SearchBooks)
+	router.GET("/search/authors", h.SearchAuthors)
+}
+
+func (h *searchHandler) SearchBooks(ctx *gin.Context) {
+	limit, offset, ok := searchPage(ctx)
+	if !ok {
+		return
+	}
+	books, inError := h.searchService.SearchBooks(ctx.Request.Context(), ctx.Query("q"), limit, offset)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, books)
+}
+
+func (h *searchHandler) SearchAuthors(ctx *gin.Context) {
+	limit, offset, ok := searchPage(ctx)
+	if !ok {
+		return
+	}
+	authors, inError := h.searchService.SearchAuthors(ctx.Request.Context(), ctx.Query("q"), limit, offset)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, authors)
+}
+
+// searchPage читает limit и offset; при ошибке уже ответил клиенту
+func searchPage(ctx *gin.Context) (int, int, bool) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultSearchLimit)))
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		middlewares.AbortWithProblem(ctx, invalidLimit(maxSearchLimit))
+		return 0, 0, false
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		middlewares.AbortWithProblem(ctx, invalidOffset())
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
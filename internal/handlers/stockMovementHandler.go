@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMovementsLimit = 50
+	maxMovementsLimit     = 500
+)
+
+type StockMovementHandlerInterface interface {
+	router.HandlerInterface
+	GetBookMovements(ctx *gin.Context)
+}
+
+type stockMovementHandler struct {
+	stockMovementService services.StockMovementServiceInterface
+}
+
+func NewStockMovementHandler(stockMovementService services.StockMovementServiceInterface) StockMovementHandlerInterface {
+	return &stockMovementHandler{stockMovementService: stockMovementService}
+}
+
+func (h *stockMovementHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/books/:id/movements", middlewares.RequireRole(models.RoleClerk, models.RoleManager), h.GetBookMovements)
+}
+
+func (h *stockMovementHandler) GetBookMovements(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
+	var fromPtr, toPtr *time.Time
+	if ctx.Query("from") != "" {
+		from, err := time.Parse(time.RFC3339, ctx.Query("from"))
+		if err != nil {
+			middlewares.AbortWithProblem(ctx, invalidDate("from", "RFC 3339"))
+			return
+		}
+		fromPtr = &from
+	}
+	if ctx.Query("to") != "" {
+		to, err := time.Parse(time.RFC3339, ctx.Query("to"))
+		if err != nil {
+			middlewares.AbortWithProblem(ctx, invalidDate("to", "RFC 3339"))
+			return
+		}
+		toPtr = &to
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultMovementsLimit)))
+	if err != nil || limit < 1 || limit > maxMovementsLimit {
+		middlewares.AbortWithProblem(ctx, invalidLimit(maxMovementsLimit))
+		return
+	}
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		middlewares.AbortWithProblem(ctx, invalidOffset())
+		return
+	}
+	movements, inError := h.stockMovementService.GetByBook(ctx.Request.Context(), bookID, fromPtr, toPtr, limit, offset)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, movements)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TrashHandlerInterface interface {
+	router.HandlerInterface
+	GetTrash(ctx *gin.Context)
+	RestoreBook(ctx *gin.Context)
+	RestoreAuthor(ctx *gin.Context)
+	PurgeBook(ctx *gin.Context)
+	PurgeAuthor(ctx *gin.Context)
+}
+
+type trashHandler struct {
+	trashService services.TrashServiceInterface
+}
+
+func NewTrashHandler(trashService services.TrashServiceInterface) TrashHandlerInterface {
+	return &trashHandler{trashService: trashService}
+}
+
+func (h *trashHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admins := router.Group("/trash", middlewares.RequireRole(models.RoleAdmin))
+	admins.GET("", h.GetTrash)
+	admins.POST("/books/:id/restore", h.RestoreBook)
+	admins.POST("/authors/:id/restore", h.RestoreAuthor)
+	admins.DELETE("/books/:id", h.PurgeBook)
+	admins.DELETE("/authors/:id", h.PurgeAuthor)
+}
+
+func (h *trashHandler) GetTrash(ctx *gin.Context) {
+	trash, inError := h.trashService.Get(ctx.Request.Context())
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, trash)
+}
+
+func (h *trashHandler) RestoreBook(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
+	if inError := h.trashService.RestoreBook(ctx.Request.Context(), bookID); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *trashHandler) RestoreAuthor(ctx *gin.Context) {
+	authorID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("author"))
+		return
+	}
+	if inError := h.trashService.RestoreAuthor(ctx.Request.Context(), authorID); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *trashHandler) PurgeBook(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
+	if inError := h.trashService.PurgeBook(ctx.Request.Context(), bookID); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *trashHandler) PurgeAuthor(ctx *gin.Context) {
+	authorID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("author"))
+		return
+	}
+	if inError := h.trashService.PurgeAuthor(ctx.Request.Context(), authorID); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UserHandlerInterface interface {
+	router.HandlerInterface
+	CreateUser(ctx *gin.Context)
+	GetAllUsers(ctx *gin.Context)
+	FindUserById(ctx *gin.Context)
+	DisableUser(ctx *gin.Context)
+	EnableUser(ctx *gin.Context)
+	ResetPassword(ctx *gin.Context)
+	GetMe(ctx *gin.Context)
+	ChangeMyPassword(ctx *gin.Context)
+}
+
+type userHandler struct {
+	userService services.UserServiceInterface
+}
+
+func NewUserHandler(userService services.UserServiceInterface) UserHandlerInterface {
+	return &userHandler{userService: userService}
+}
+
+func (h *userHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/users/me", h.GetMe)
+	router.PUT("/users/me/password", h.ChangeMyPassword)
+
+	admins := router.Group("/users", middlewares.RequireRole(models.RoleAdmin))
+	admins.POST("", h.CreateUser)
+	admins.GET("", h.GetAllUsers)
+	admins.GET("/:id", h.FindUserById)
+	admins.POST("/:id/disable", h.DisableUser)
+	admins.POST("/:id/enable", h.EnableUser)
+	admins.PUT("/:id/password", h.ResetPassword)
+}
+
+func (h *userHandler) CreateUser(ctx *gin.Context) {
+	var createUserRequest models.CreateUserRequest
+	if err := ctx.ShouldBindJSON(&createUserRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	createUserResponse, inError := h.userService.Create(ctx.Request.Context(), createUserRequest)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, createUserResponse)
+}
+
+func (h *userHandler) GetAllUsers(ctx *gin.Context) {
+	users, inError := h.userService.GetAll(ctx.Request.Context())
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, users)
+}
+
+func (h *userHandler) FindUserById(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("user"))
+		return
+	}
+	user, inError := h.userService.FindById(ctx.Request.Context(), userID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (h *userHandler) DisableUser(ctx *gin.Context) {
+	h.setDisabled(ctx, true)
+}
+
+func (h *userHandler) EnableUser(ctx *gin.Context) {
+	h.setDisabled(ctx, false)
+}
+
+func (h *userHandler) setDisabled(ctx *gin.Context, disabled bool) {
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("user"))
+		return
+	}
+	if identity, ok := middlewares.GetIdentity(ctx); ok && identity.UserID == userID && disabled {
+		middlewares.AbortWithProblem(ctx, apperrors.Conflict("cannot_disable_self", "cannot disable yourself"))
+		return
+	}
+	if inError := h.userService.SetDisabled(ctx.Request.Context(), userID, disabled); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *userHandler) ResetPassword(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("user"))
+		return
+	}
+	var resetPasswordRequest models.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&resetPasswordRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	if inError := h.userService.ResetPassword(ctx.Request.Context(), userID, resetPasswordRequest); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *userHandler) GetMe(ctx *gin.Context) {
+	identity, ok := middlewares.GetIdentity(ctx)
+	if !ok {
+		middlewares.AbortWithProblem(ctx, apperrors.Unauthorized("authentication_required", "authentication required"))
+		return
+	}
+	user, inError := h.userService.FindById(ctx.Request.Context(), identity.UserID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (h *userHandler) ChangeMyPassword(ctx *gin.Context) {
+	identity, ok := middlewares.GetIdentity(ctx)
+	if !ok {
+		middlewares.AbortWithProblem(ctx, apperrors.Unauthorized("authentication_required", "authentication required"))
+		return
+	}
+	var changePasswordRequest models.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&changePasswordRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	if inError := h.userService.ChangePassword(ctx.Request.Context(), identity.UserID, changePasswordRequest); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
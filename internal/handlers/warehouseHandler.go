@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"gin_main/internal/models"
+	"gin_main/internal/services"
+	"net/http"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"gin_main/pkg/httpserver/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WarehouseHandlerInterface interface {
+	router.HandlerInterface
+	CreateWarehouse(ctx *gin.Context)
+	GetAllWarehouses(ctx *gin.Context)
+	CreateLocation(ctx *gin.Context)
+	GetLocations(ctx *gin.Context)
+	GetWarehouseStock(ctx *gin.Context)
+	GetBookStock(ctx *gin.Context)
+	MoveStock(ctx *gin.Context)
+}
+
+type warehouseHandler struct {
+	warehouseService services.WarehouseServiceInterface
+}
+
+func NewWarehouseHandler(warehouseService services.WarehouseServiceInterface) WarehouseHandlerInterface {
+	return &warehouseHandler{warehouseService: warehouseService}
+}
+
+func (h *warehouseHandler) RegisterRoutes(router *gin.RouterGroup) {
+	managers := middlewares.RequireRole(models.RoleManager)
+
+	router.POST("/warehouses", managers, h.CreateWarehouse)
+	router.GET("/warehouses", h.GetAllWarehouses)
+	router.POST("/warehouses/:id/locations", managers, h.CreateLocation)
+	router.GET("/warehouses/:id/locations", h.GetLocations)
+	router.GET("/warehouses/:id/stock", h.GetWarehouseStock)
+	router.GET("/books/:id/stock", h.GetBookStock)
+	router.POST("/stock/moves", middlewares.RequireRole(models.RoleClerk), h.MoveStock)
+}
+
+func (h *warehouseHandler) CreateWarehouse(ctx *gin.Context) {
+	var createWarehouseRequest models.CreateWarehouseRequest
+	if err := ctx.ShouldBindJSON(&createWarehouseRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	createWarehouseResponse, inError := h.warehouseService.CreateWarehouse(ctx.Request.Context(), createWarehouseRequest)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, createWarehouseResponse)
+}
+
+func (h *warehouseHandler) GetAllWarehouses(ctx *gin.Context) {
+	warehouses, inError := h.warehouseService.GetAllWarehouses(ctx.Request.Context())
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, warehouses)
+}
+
+func (h *warehouseHandler) CreateLocation(ctx *gin.Context) {
+	warehouseID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("warehouse"))
+		return
+	}
+	var createLocationRequest models.CreateLocationRequest
+	if err := ctx.ShouldBindJSON(&createLocationRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	createLocationResponse, inError := h.warehouseService.CreateLocation(ctx.Request.Context(), warehouseID, createLocationRequest)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, createLocationResponse)
+}
+
+func (h *warehouseHandler) GetLocations(ctx *gin.Context) {
+	warehouseID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("warehouse"))
+		return
+	}
+	locations, inError := h.warehouseService.GetLocations(ctx.Request.Context(), warehouseID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, locations)
+}
+
+func (h *warehouseHandler) GetWarehouseStock(ctx *gin.Context) {
+	warehouseID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("warehouse"))
+		return
+	}
+	stock, inError := h.warehouseService.GetWarehouseStock(ctx.Request.Context(), warehouseID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, stock)
+}
+
+func (h *warehouseHandler) GetBookStock(ctx *gin.Context) {
+	bookID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		middlewares.AbortWithProblem(ctx, invalidID("book"))
+		return
+	}
+	stock, inError := h.warehouseService.GetBookStock(ctx.Request.Context(), bookID)
+	if inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.JSON(http.StatusOK, stock)
+}
+
+func (h *warehouseHandler) MoveStock(ctx *gin.Context) {
+	var moveStockRequest models.MoveStockRequest
+	if err := ctx.ShouldBindJSON(&moveStockRequest); err != nil {
+		middlewares.AbortWithProblem(ctx, apperrors.FromBinding(err))
+		return
+	}
+	if inError := h.warehouseService.MoveStock(ctx.Request.Context(), moveStockRequest, actorFromContext(ctx)); inError != nil {
+		middlewares.AbortWithProblem(ctx, inError)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,126 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"gin_main/config"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type TokenType string
+
+const (
+	AccessToken  TokenType = "access"
+	RefreshToken TokenType = "refresh"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Claims struct {
+	Login string    `json:"login"`
+	Role  string    `json:"role"`
+	Type  TokenType `json:"typ"`
+	jwtlib.RegisteredClaims
+}
+
+type Tokens struct {
+	AccessToken      string
+	RefreshToken     string
+	AccessExpiresAt  time.Time
+	RefreshExpiresAt time.Time
+}
+
+type JWTHelperInterface interface {
+	GenerateTokens(userID uuid.UUID, login, role string) (Tokens, error) // выпускает пару access + refresh токенов
+	ParseAccessToken(token string) (*Claims, error)                      // проверяет подпись, срок и тип access токена
+	ParseRefreshToken(token string) (*Claims, error)                     // проверяет подпись, срок и тип refresh токена
+}
+
+type jwtHelper struct {
+	accessSecret  []byte
+	refreshSecret []byte
+	accessTTL     time.Duration
+	refreshTTL    time.Duration
+	issuer        string
+}
+
+func NewJWTHelper(config *config.Config) JWTHelperInterface {
+	return &jwtHelper{
+		accessSecret:  []byte(config.Auth.AccessSecret),
+		refreshSecret: []byte(config.Auth.RefreshSecret),
+		accessTTL:     config.Auth.AccessTTL,
+		refreshTTL:    config.Auth.RefreshTTL,
+		issuer:        config.App,
+	}
+}
+
+func (h *jwtHelper) GenerateTokens(userID uuid.UUID, login, role string) (Tokens, error) {
+	now := time.Now()
+	accessExpiresAt := now.Add(h.accessTTL)
+	accessToken, err := h.sign(h.accessSecret, h.newClaims(userID, login, role, AccessToken, now, accessExpiresAt))
+	if err != nil {
+		return Tokens{}, err
+	}
+	refreshExpiresAt := now.Add(h.refreshTTL)
+	refreshToken, err := h.sign(h.refreshSecret, h.newClaims(userID, login, role, RefreshToken, now, refreshExpiresAt))
+	if err != nil {
+		return Tokens{}, err
+	}
+	return Tokens{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+func (h *jwtHelper) ParseAccessToken(token string) (*Claims, error) {
+	return h.parse(token, h.accessSecret, AccessToken)
+}
+
+func (h *jwtHelper) ParseRefreshToken(token string) (*Claims, error) {
+	return h.parse(token, h.refreshSecret, RefreshToken)
+}
+
+func (h *jwtHelper) newClaims(userID uuid.UUID, login, role string, tokenType TokenType, issuedAt, expiresAt time.Time) Claims {
+	return Claims{
+		Login: login,
+		Role:  role,
+		Type:  tokenType,
+		RegisteredClaims: jwtlib.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    h.issuer,
+			Subject:   userID.String(),
+			IssuedAt:  jwtlib.NewNumericDate(issuedAt),
+			ExpiresAt: jwtlib.NewNumericDate(expiresAt),
+		},
+	}
+}
+
+func (h *jwtHelper) sign(secret []byte, claims Claims) (string, error) {
+	return jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims).SignedString(secret)
+}
+
+func (h *jwtHelper) parse(token string, secret []byte, tokenType TokenType) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwtlib.ParseWithClaims(token, claims, func(*jwtlib.Token) (any, error) {
+		return secret, nil
+	},
+		jwtlib.WithValidMethods([]string{jwtlib.SigningMethodHS256.Alg()}),
+		jwtlib.WithIssuer(h.issuer),
+		jwtlib.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("%w: unexpected token type %q", ErrInvalidToken, claims.Type)
+	}
+	if _, err := uuid.Parse(claims.Subject); err != nil {
+		return nil, fmt.Errorf("%w: subject is not a user id", ErrInvalidToken)
+	}
+	return claims, nil
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/config"
+	"gin_main/pkg/database"
+	"io"
+	"strconv"
+
+	"github.com/rs/zerolog"
+)
+
+const usage = "usage: migrate up|down|status|to N"
+
+// Migrate применяет все миграции при старте сервиса
+func Migrate(config *config.Config, log *zerolog.Logger) {
+	if err := Run(config, log, io.Discard, []string{"up"}); err != nil {
+		log.Fatal().Err(err).Msg("Cannot apply migrations")
+	}
+}
+
+// Run выполняет подкоманду migrate, вывод status пишется в out
+func Run(config *config.Config, log *zerolog.Logger, out io.Writer, args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	db, err := database.NewDatabaseConnection(config).DB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := NewMigrator(db, log)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		return migrator.Up(ctx)
+	case args[0] == "down" && len(args) == 1:
+		return migrator.Down(ctx)
+	case args[0] == "to" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q: %s", args[1], usage)
+		}
+		return migrator.To(ctx, version)
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(out, "%04d  %-30s  %s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	default:
+		return errors.New(usage)
+	}
+}
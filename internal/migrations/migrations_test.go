@@ -0,0 +1,18 @@
+package migrations
+
+import "testing"
+
+func TestLoadEmbeddedMigrations(t *testing.T) {
+	migrations, err := load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %q has version %d, want %d", migration.Name, migration.Version, i+1)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+CREATE TABLE authors (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    date_of_birth date,
+    first_name    text NOT NULL DEFAULT '',
+    second_name   text NOT NULL DEFAULT '',
+    surname       text NOT NULL
+);
+
+CREATE TABLE books (
+    id              uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    date_of_writing date,
+    title           text    NOT NULL,
+    author_id       uuid    NOT NULL REFERENCES authors (id) ON DELETE RESTRICT,
+    quantity        integer NOT NULL DEFAULT 0,
+    CONSTRAINT books_quantity_non_negative CHECK (quantity >= 0)
+);
+
+CREATE INDEX books_author_id_idx ON books (author_id);
+
+CREATE TABLE users (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    login         text        NOT NULL,
+    password_hash text        NOT NULL,
+    role          text        NOT NULL,
+    disabled      boolean     NOT NULL DEFAULT false,
+    last_login_at timestamptz,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT users_login_key UNIQUE (login),
+    CONSTRAINT users_role_check CHECK (role IN ('viewer', 'clerk', 'manager', 'admin'))
+);
@@ -0,0 +1,11 @@
+ALTER TABLE books ADD COLUMN quantity integer NOT NULL DEFAULT 0;
+ALTER TABLE books ADD CONSTRAINT books_quantity_non_negative CHECK (quantity >= 0);
+
+UPDATE books b
+SET quantity = s.total
+FROM (SELECT book_id, sum(quantity) AS total FROM stock_levels GROUP BY book_id) s
+WHERE s.book_id = b.id;
+
+DROP TABLE IF EXISTS stock_levels;
+DROP TABLE IF EXISTS locations;
+DROP TABLE IF EXISTS warehouses;
@@ -0,0 +1,41 @@
+CREATE TABLE warehouses (
+    id      uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    code    text NOT NULL,
+    name    text NOT NULL,
+    address text NOT NULL DEFAULT '',
+    CONSTRAINT warehouses_code_key UNIQUE (code)
+);
+
+CREATE TABLE locations (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    warehouse_id uuid NOT NULL REFERENCES warehouses (id) ON DELETE RESTRICT,
+    aisle        text NOT NULL,
+    shelf        text NOT NULL,
+    bin          text NOT NULL,
+    CONSTRAINT locations_address_key UNIQUE (warehouse_id, aisle, shelf, bin)
+);
+
+CREATE TABLE stock_levels (
+    book_id     uuid    NOT NULL REFERENCES books (id) ON DELETE RESTRICT,
+    location_id uuid    NOT NULL REFERENCES locations (id) ON DELETE RESTRICT,
+    quantity    integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, location_id),
+    CONSTRAINT stock_levels_quantity_non_negative CHECK (quantity >= 0)
+);
+
+CREATE INDEX stock_levels_location_id_idx ON stock_levels (location_id);
+
+-- существующие остатки переносятся в ячейку по умолчанию основного склада
+WITH main_warehouse AS (
+    INSERT INTO warehouses (code, name) VALUES ('MAIN', 'Main warehouse') RETURNING id
+), default_location AS (
+    INSERT INTO locations (warehouse_id, aisle, shelf, bin)
+    SELECT id, '0', '0', '0' FROM main_warehouse
+    RETURNING id
+)
+INSERT INTO stock_levels (book_id, location_id, quantity)
+SELECT b.id, l.id, b.quantity
+FROM books b CROSS JOIN default_location l
+WHERE b.quantity > 0;
+
+ALTER TABLE books DROP COLUMN quantity;
@@ -0,0 +1,28 @@
+CREATE TABLE stock_movements (
+    id               bigserial PRIMARY KEY,
+    book_id          uuid        NOT NULL REFERENCES books (id) ON DELETE RESTRICT,
+    location_id      uuid        NOT NULL REFERENCES locations (id) ON DELETE RESTRICT,
+    delta            integer     NOT NULL,
+    location_balance integer     NOT NULL,
+    balance          integer     NOT NULL,
+    reason           text        NOT NULL,
+    actor_id         uuid REFERENCES users (id) ON DELETE SET NULL,
+    correlation_id   text        NOT NULL DEFAULT '',
+    comment          text        NOT NULL DEFAULT '',
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT stock_movements_delta_non_zero CHECK (delta <> 0),
+    CONSTRAINT stock_movements_reason_check CHECK (reason IN ('receipt', 'sale', 'adjustment', 'damage', 'return', 'transfer'))
+);
+
+CREATE INDEX stock_movements_book_id_created_at_idx ON stock_movements (book_id, created_at, id);
+
+-- журнал только дополняется: изменение и удаление строк запрещены
+CREATE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
@@ -0,0 +1,14 @@
+CREATE TABLE idempotency_keys (
+    scope         text        NOT NULL,
+    key           text        NOT NULL,
+    request_hash  text        NOT NULL,
+    completed     boolean     NOT NULL DEFAULT false,
+    status_code   integer,
+    content_type  text        NOT NULL DEFAULT '',
+    response_body bytea,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    expires_at    timestamptz NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,16 @@
+CREATE TABLE reservations (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    book_id    uuid        NOT NULL REFERENCES books (id) ON DELETE RESTRICT,
+    quantity   integer     NOT NULL,
+    status     text        NOT NULL DEFAULT 'active',
+    reference  text        NOT NULL DEFAULT '',
+    expires_at timestamptz NOT NULL,
+    created_by uuid REFERENCES users (id) ON DELETE SET NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT reservations_quantity_positive CHECK (quantity > 0),
+    CONSTRAINT reservations_status_check CHECK (status IN ('active', 'confirmed', 'cancelled', 'expired'))
+);
+
+CREATE INDEX reservations_active_book_id_idx ON reservations (book_id) WHERE status = 'active';
+CREATE INDEX reservations_active_expires_at_idx ON reservations (expires_at) WHERE status = 'active';
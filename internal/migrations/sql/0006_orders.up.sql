@@ -0,0 +1,22 @@
+CREATE TABLE orders (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_name text        NOT NULL,
+    comment       text        NOT NULL DEFAULT '',
+    status        text        NOT NULL DEFAULT 'draft',
+    created_by    uuid REFERENCES users (id) ON DELETE SET NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT orders_status_check CHECK (status IN ('draft', 'placed', 'picking', 'packed', 'shipped', 'cancelled'))
+);
+
+CREATE INDEX orders_status_idx ON orders (status);
+
+CREATE TABLE order_lines (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id       uuid    NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    book_id        uuid    NOT NULL REFERENCES books (id) ON DELETE RESTRICT,
+    quantity       integer NOT NULL,
+    reservation_id uuid REFERENCES reservations (id) ON DELETE SET NULL,
+    CONSTRAINT order_lines_quantity_positive CHECK (quantity > 0),
+    CONSTRAINT order_lines_book_key UNIQUE (order_id, book_id)
+);
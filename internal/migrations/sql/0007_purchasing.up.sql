@@ -0,0 +1,53 @@
+CREATE TABLE suppliers (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       text        NOT NULL,
+    email      text        NOT NULL DEFAULT '',
+    phone      text        NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT suppliers_name_key UNIQUE (name)
+);
+
+CREATE TABLE purchase_orders (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    supplier_id uuid        NOT NULL REFERENCES suppliers (id) ON DELETE RESTRICT,
+    status      text        NOT NULL DEFAULT 'open',
+    expected_at date        NOT NULL,
+    comment     text        NOT NULL DEFAULT '',
+    created_by  uuid REFERENCES users (id) ON DELETE SET NULL,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT purchase_orders_status_check CHECK (status IN ('open', 'partial', 'received', 'closed'))
+);
+
+CREATE INDEX purchase_orders_pending_idx ON purchase_orders (expected_at) WHERE status IN ('open', 'partial');
+
+CREATE TABLE purchase_order_lines (
+    id                uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    purchase_order_id uuid    NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    book_id           uuid    NOT NULL REFERENCES books (id) ON DELETE RESTRICT,
+    expected_quantity integer NOT NULL,
+    received_quantity integer NOT NULL DEFAULT 0,
+    damaged_quantity  integer NOT NULL DEFAULT 0,
+    CONSTRAINT purchase_order_lines_expected_positive CHECK (expected_quantity > 0),
+    CONSTRAINT purchase_order_lines_received_non_negative CHECK (received_quantity >= 0 AND damaged_quantity >= 0),
+    CONSTRAINT purchase_order_lines_book_key UNIQUE (purchase_order_id, book_id)
+);
+
+-- каждая приёмка по заказу поставщику хранится отдельно, чтобы видеть историю частичных поставок
+CREATE TABLE purchase_receipts (
+    id                uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    purchase_order_id uuid        NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    location_id       uuid        NOT NULL REFERENCES locations (id) ON DELETE RESTRICT,
+    received_by       uuid REFERENCES users (id) ON DELETE SET NULL,
+    comment           text        NOT NULL DEFAULT '',
+    created_at        timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE purchase_receipt_lines (
+    receipt_id uuid    NOT NULL REFERENCES purchase_receipts (id) ON DELETE CASCADE,
+    line_id    uuid    NOT NULL REFERENCES purchase_order_lines (id) ON DELETE CASCADE,
+    received   integer NOT NULL,
+    damaged    integer NOT NULL,
+    PRIMARY KEY (receipt_id, line_id),
+    CONSTRAINT purchase_receipt_lines_quantity_check CHECK (received >= 0 AND damaged >= 0 AND received + damaged > 0)
+);
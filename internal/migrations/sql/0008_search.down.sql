@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS books_title_trgm_idx;
+DROP INDEX IF EXISTS books_search_vector_idx;
+DROP TRIGGER IF EXISTS authors_books_search_vector_refresh ON authors;
+DROP FUNCTION IF EXISTS authors_books_search_vector_refresh();
+DROP TRIGGER IF EXISTS books_search_vector_refresh ON books;
+DROP FUNCTION IF EXISTS books_search_vector_refresh();
+ALTER TABLE books DROP COLUMN IF EXISTS search_vector;
+DROP INDEX IF EXISTS authors_surname_trgm_idx;
+DROP INDEX IF EXISTS authors_search_vector_idx;
+ALTER TABLE authors DROP COLUMN IF EXISTS search_vector;
+DROP EXTENSION IF EXISTS pg_trgm;
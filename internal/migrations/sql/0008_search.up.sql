@@ -0,0 +1,50 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE authors
+    ADD COLUMN search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector('simple', surname || ' ' || first_name || ' ' || second_name)) STORED;
+
+CREATE INDEX authors_search_vector_idx ON authors USING gin (search_vector);
+CREATE INDEX authors_surname_trgm_idx ON authors USING gin (lower(surname) gin_trgm_ops);
+
+-- вектор книги включает имя автора, чтобы запрос "толстой война" находил книгу одним индексом
+ALTER TABLE books ADD COLUMN search_vector tsvector NOT NULL DEFAULT ''::tsvector;
+
+CREATE FUNCTION books_search_vector_refresh() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('russian', NEW.title), 'A') ||
+        setweight(to_tsvector('simple', NEW.title), 'A') ||
+        setweight(coalesce((SELECT search_vector FROM authors WHERE id = NEW.author_id), ''::tsvector), 'B');
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER books_search_vector_refresh
+    BEFORE INSERT OR UPDATE OF title, author_id
+    ON books
+    FOR EACH ROW
+EXECUTE FUNCTION books_search_vector_refresh();
+
+-- при переименовании автора пересчитываем векторы его книг через триггер выше
+CREATE FUNCTION authors_books_search_vector_refresh() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    UPDATE books SET title = title WHERE author_id = NEW.id;
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER authors_books_search_vector_refresh
+    AFTER UPDATE OF surname, first_name, second_name
+    ON authors
+    FOR EACH ROW
+EXECUTE FUNCTION authors_books_search_vector_refresh();
+
+UPDATE books SET title = title;
+
+CREATE INDEX books_search_vector_idx ON books USING gin (search_vector);
+CREATE INDEX books_title_trgm_idx ON books USING gin (lower(title) gin_trgm_ops);
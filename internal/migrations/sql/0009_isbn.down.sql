@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS books_isbn_key;
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_isbn_format_check;
+ALTER TABLE books DROP COLUMN IF EXISTS isbn;
@@ -0,0 +1,3 @@
+ALTER TABLE books ADD COLUMN isbn text;
+ALTER TABLE books ADD CONSTRAINT books_isbn_format_check CHECK (isbn ~ '^97[89][0-9]{10}$');
+CREATE UNIQUE INDEX books_isbn_key ON books (isbn) WHERE isbn IS NOT NULL;
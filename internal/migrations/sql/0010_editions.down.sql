@@ -0,0 +1,65 @@
+ALTER TABLE books ADD COLUMN author_id uuid REFERENCES authors (id) ON DELETE RESTRICT;
+UPDATE books b
+SET author_id = (SELECT bc.author_id
+                 FROM book_contributors bc
+                 WHERE bc.book_id = b.id
+                 ORDER BY bc.role <> 'author', bc.position
+                 LIMIT 1);
+ALTER TABLE books ALTER COLUMN author_id SET NOT NULL;
+CREATE INDEX books_author_id_idx ON books (author_id);
+
+DROP TRIGGER IF EXISTS book_contributors_delete_search_vector_refresh ON book_contributors;
+DROP TRIGGER IF EXISTS book_contributors_insert_search_vector_refresh ON book_contributors;
+DROP FUNCTION IF EXISTS book_contributors_search_vector_refresh();
+
+CREATE OR REPLACE FUNCTION authors_books_search_vector_refresh() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    UPDATE books SET title = title WHERE author_id = NEW.id;
+    RETURN NULL;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION books_search_vector_refresh() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('russian', NEW.title), 'A') ||
+        setweight(to_tsvector('simple', NEW.title), 'A') ||
+        setweight(coalesce((SELECT search_vector FROM authors WHERE id = NEW.author_id), ''::tsvector), 'B');
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER books_search_vector_refresh ON books;
+CREATE TRIGGER books_search_vector_refresh
+    BEFORE INSERT OR UPDATE OF title, author_id
+    ON books
+    FOR EACH ROW
+EXECUTE FUNCTION books_search_vector_refresh();
+
+UPDATE books SET title = title;
+
+ALTER TABLE stock_movements DROP COLUMN edition_id;
+
+-- остатки изданий одной книги в ячейке снова сливаются в одну строку
+CREATE TEMPORARY TABLE stock_levels_by_book ON COMMIT DROP AS
+SELECT book_id, location_id, sum(quantity)::integer AS quantity
+FROM stock_levels
+GROUP BY book_id, location_id;
+DROP TABLE stock_levels;
+CREATE TABLE stock_levels (
+    book_id     uuid    NOT NULL REFERENCES books (id) ON DELETE RESTRICT,
+    location_id uuid    NOT NULL REFERENCES locations (id) ON DELETE RESTRICT,
+    quantity    integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, location_id),
+    CONSTRAINT stock_levels_quantity_non_negative CHECK (quantity >= 0)
+);
+CREATE INDEX stock_levels_location_id_idx ON stock_levels (location_id);
+INSERT INTO stock_levels (book_id, location_id, quantity)
+SELECT book_id, location_id, quantity FROM stock_levels_by_book;
+
+DROP TABLE editions;
+DROP TABLE book_contributors;
@@ -0,0 +1,109 @@
+-- у книги может быть несколько участников с разными ролями; порядок задаёт, как они перечисляются
+CREATE TABLE book_contributors (
+    book_id   uuid    NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    author_id uuid    NOT NULL REFERENCES authors (id) ON DELETE RESTRICT,
+    role      text    NOT NULL DEFAULT 'author',
+    position  integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, author_id, role),
+    CONSTRAINT book_contributors_role_check CHECK (role IN ('author', 'translator', 'editor', 'illustrator'))
+);
+
+CREATE INDEX book_contributors_author_id_idx ON book_contributors (author_id);
+
+INSERT INTO book_contributors (book_id, author_id, role, position)
+SELECT id, author_id, 'author', 0 FROM books;
+
+CREATE TABLE editions (
+    id               uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    book_id          uuid        NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    format           text        NOT NULL DEFAULT 'unspecified',
+    publisher        text        NOT NULL DEFAULT '',
+    language         text        NOT NULL DEFAULT '',
+    page_count       integer,
+    publication_year integer,
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT editions_format_check CHECK (format IN ('hardcover', 'paperback', 'unspecified')),
+    CONSTRAINT editions_page_count_positive CHECK (page_count > 0),
+    -- нужен для составного внешнего ключа остатков: издание всегда относится к той же книге, что и строка остатка
+    CONSTRAINT editions_id_book_id_key UNIQUE (id, book_id)
+);
+
+CREATE INDEX editions_book_id_idx ON editions (book_id);
+
+-- у каждой существующей книги появляется издание без описания, к нему относятся все её остатки
+INSERT INTO editions (book_id)
+SELECT id FROM books;
+
+-- остаток хранится по изданию; book_id остаётся в строке, чтобы суммы по книге не требовали соединения
+ALTER TABLE stock_levels ADD COLUMN edition_id uuid;
+UPDATE stock_levels s SET edition_id = e.id FROM editions e WHERE e.book_id = s.book_id;
+ALTER TABLE stock_levels ALTER COLUMN edition_id SET NOT NULL;
+ALTER TABLE stock_levels DROP CONSTRAINT stock_levels_pkey;
+ALTER TABLE stock_levels ADD PRIMARY KEY (edition_id, location_id);
+ALTER TABLE stock_levels
+    ADD CONSTRAINT stock_levels_edition_fkey FOREIGN KEY (edition_id, book_id) REFERENCES editions (id, book_id) ON DELETE RESTRICT;
+CREATE INDEX stock_levels_book_id_idx ON stock_levels (book_id);
+
+ALTER TABLE stock_movements ADD COLUMN edition_id uuid REFERENCES editions (id) ON DELETE RESTRICT;
+ALTER TABLE stock_movements DISABLE TRIGGER stock_movements_append_only;
+UPDATE stock_movements m SET edition_id = e.id FROM editions e WHERE e.book_id = m.book_id;
+ALTER TABLE stock_movements ENABLE TRIGGER stock_movements_append_only;
+ALTER TABLE stock_movements ALTER COLUMN edition_id SET NOT NULL;
+
+-- вектор книги теперь собирается из всех её участников
+CREATE OR REPLACE FUNCTION books_search_vector_refresh() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('russian', NEW.title), 'A') ||
+        setweight(to_tsvector('simple', NEW.title), 'A') ||
+        setweight(to_tsvector('simple', coalesce((SELECT string_agg(a.surname || ' ' || a.first_name || ' ' || a.second_name, ' ')
+                                                  FROM book_contributors bc
+                                                           JOIN authors a ON a.id = bc.author_id
+                                                  WHERE bc.book_id = NEW.id), '')), 'B');
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER books_search_vector_refresh ON books;
+CREATE TRIGGER books_search_vector_refresh
+    BEFORE INSERT OR UPDATE OF title
+    ON books
+    FOR EACH ROW
+EXECUTE FUNCTION books_search_vector_refresh();
+
+CREATE OR REPLACE FUNCTION authors_books_search_vector_refresh() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    UPDATE books SET title = title WHERE id IN (SELECT book_id FROM book_contributors WHERE author_id = NEW.id);
+    RETURN NULL;
+END;
+$$;
+
+-- участники добавляются после вставки книги, поэтому вектор пересчитывается и при изменении их состава
+CREATE FUNCTION book_contributors_search_vector_refresh() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    UPDATE books SET title = title WHERE id IN (SELECT book_id FROM changed);
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER book_contributors_insert_search_vector_refresh
+    AFTER INSERT ON book_contributors
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION book_contributors_search_vector_refresh();
+
+CREATE TRIGGER book_contributors_delete_search_vector_refresh
+    AFTER DELETE ON book_contributors
+    REFERENCING OLD TABLE AS changed
+    FOR EACH STATEMENT
+EXECUTE FUNCTION book_contributors_search_vector_refresh();
+
+ALTER TABLE books DROP COLUMN author_id;
+
+UPDATE books SET title = title;
@@ -0,0 +1,19 @@
+-- после отката записи из корзины снова становятся видимыми; ISBN остаётся у живой книги,
+-- а среди удалённых — у удалённой последней
+UPDATE books b
+SET isbn = NULL
+WHERE b.deleted_at IS NOT NULL
+  AND b.isbn IS NOT NULL
+  AND EXISTS (SELECT 1 FROM books o WHERE o.isbn = b.isbn AND o.id <> b.id
+                AND (o.deleted_at IS NULL OR (o.deleted_at, o.id) > (b.deleted_at, b.id)));
+
+DROP INDEX IF EXISTS books_isbn_key;
+CREATE UNIQUE INDEX books_isbn_key ON books (isbn) WHERE isbn IS NOT NULL;
+
+DROP INDEX IF EXISTS authors_deleted_at_idx;
+DROP INDEX IF EXISTS books_deleted_at_idx;
+
+ALTER TABLE authors DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE authors DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,14 @@
+ALTER TABLE books
+    ADD COLUMN deleted_at timestamptz,
+    ADD COLUMN deleted_by uuid REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE authors
+    ADD COLUMN deleted_at timestamptz,
+    ADD COLUMN deleted_by uuid REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX books_deleted_at_idx ON books (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX authors_deleted_at_idx ON authors (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- книга в корзине не занимает свой ISBN; при восстановлении конфликт проверит тот же индекс
+DROP INDEX books_isbn_key;
+CREATE UNIQUE INDEX books_isbn_key ON books (isbn) WHERE isbn IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE authors DROP COLUMN IF EXISTS version;
+ALTER TABLE books DROP COLUMN IF EXISTS version;
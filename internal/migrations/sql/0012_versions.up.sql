@@ -0,0 +1,3 @@
+-- номер версии записи для оптимистической блокировки; увеличивается при каждом изменении через API
+ALTER TABLE books ADD COLUMN version integer NOT NULL DEFAULT 1 CHECK (version > 0);
+ALTER TABLE authors ADD COLUMN version integer NOT NULL DEFAULT 1 CHECK (version > 0);
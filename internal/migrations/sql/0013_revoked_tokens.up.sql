@@ -0,0 +1,9 @@
+-- отозванные refresh токены: отзыв должен переживать перезапуск и быть виден всем инстансам
+CREATE TABLE revoked_tokens (
+    token_id   text PRIMARY KEY,
+    user_id    uuid        NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
@@ -0,0 +1,2 @@
+-- токены пользователя, выпущенные раньше этого момента, не принимаются; сдвигается при смене пароля и блокировке
+ALTER TABLE users ADD COLUMN tokens_valid_after timestamptz;
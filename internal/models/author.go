@@ -7,10 +7,22 @@ import (
 )
 
 type Author struct {
-	ID          uuid.UUID
-	DateOfBirth time.Time
-	FirstName   string
-	SecondName  string
-	Surname     string
-	FullName    string
+	ID          uuid.UUID `json:"authorId"`
+	DateOfBirth time.Time `json:"dateOfBirth"`
+	FirstName   string    `json:"firstName"`
+	SecondName  string    `json:"secondName"`
+	Surname     string    `json:"surname"`
+	FullName    string    `json:"fullName"`
+	Version     int       `json:"version"` // совпадает со значением ETag, передаётся в If-Match при изменении
+}
+
+type CreateOrUpdateAuthorRequest struct {
+	DateOfBirth time.Time `json:"dateOfBirth" binding:"required"`
+	FirstName   string    `json:"firstName" binding:"required,min=1,max=200"`
+	SecondName  string    `json:"secondName" binding:"max=200"`
+	Surname     string    `json:"surname" binding:"required,min=1,max=200"`
+}
+
+type CreateAuthorResponse struct {
+	ID uuid.UUID `json:"authorId" binding:"required"`
 }
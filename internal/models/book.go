@@ -6,18 +6,47 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	ContributorAuthor      = "author"
+	ContributorTranslator  = "translator"
+	ContributorEditor      = "editor"
+	ContributorIllustrator = "illustrator"
+)
+
 type Book struct {
-	ID            uuid.UUID `json:"bookId" binding:"required"`
-	DateOfWriting time.Time `json:"year" binding:"required"`
-	Title         string    `json:"title" binding:"required,min=1,max=500"`
-	Author        Author    `json:"author" binding:"required"`
-	Quantity      int       `json:"quantity" binding:"required"`
+	ID            uuid.UUID     `json:"bookId" binding:"required"`
+	DateOfWriting time.Time     `json:"year" binding:"required"`
+	Title         string        `json:"title" binding:"required,min=1,max=500"`
+	ISBN          *string       `json:"isbn"` // ISBN-13 без дефисов
+	Contributors  []Contributor `json:"contributors"`
+	Editions      []Edition     `json:"editions,omitempty"` // в выгрузке каталога не заполняется
+	OnHand        int           `json:"onHand"`             // суммарный остаток по всем складам и изданиям
+	Reserved      int           `json:"reserved"`           // удерживается активными резервами
+	Available     int           `json:"available"`          // можно зарезервировать или списать
+	Version       int           `json:"version"`            // совпадает со значением ETag, передаётся в If-Match при изменении
+}
+
+// Contributor - участник книги: автор, переводчик, редактор или иллюстратор
+type Contributor struct {
+	Author Author `json:"author"`
+	Role   string `json:"role"`
 }
 
 type CreateOrUpdateBookRequest struct {
-	DateOfWriting time.Time `json:"year" binding:"required"`
-	Title         string    `json:"title" binding:"required,min=1,max=500"`
-	Author        Author    `json:"author" binding:"required"`
+	DateOfWriting time.Time                      `json:"year" binding:"required"`
+	Title         string                         `json:"title" binding:"required,min=1,max=500"`
+	ISBN          string                         `json:"isbn" binding:"omitempty,isbn"` // ISBN-10 или ISBN-13, сохраняется как ISBN-13
+	Contributors  []ContributorRequest           `json:"contributors" binding:"omitempty,max=50,dive"`
+	AuthorID      uuid.UUID                      `json:"authorId"`                                 // краткая форма для книги с одним автором: привязка к существующему автору
+	Author        Author                         `json:"author"`                                   // краткая форма для книги с одним автором: новый автор
+	Editions      []CreateOrUpdateEditionRequest `json:"editions" binding:"omitempty,max=20,dive"` // только при создании; без них заводится одно неописанное издание
+}
+
+// ContributorRequest указывает существующего автора через authorId или нового через author
+type ContributorRequest struct {
+	AuthorID uuid.UUID `json:"authorId"`
+	Author   Author    `json:"author"`
+	Role     string    `json:"role" binding:"omitempty,oneof=author translator editor illustrator"` // по умолчанию author
 }
 
 type CreateBookResponse struct {
@@ -25,14 +54,30 @@ type CreateBookResponse struct {
 }
 
 type ChangeBookQuantityRequest struct {
-	ID       uuid.UUID `json:"bookId" binding:"required"`
-	Quantity int       `json:"quantity" binding:"required"`
+	EditionID  uuid.UUID `json:"editionId"` // можно не указывать, если у книги одно издание
+	LocationID uuid.UUID `json:"locationId" binding:"required"`
+	Quantity   int       `json:"quantity" binding:"required"`
+	Reason     string    `json:"reason" binding:"required,oneof=receipt sale adjustment damage return"`
+	Comment    string    `json:"comment" binding:"max=1000"`
 }
 
 type ChangeBookQuantityResponse struct {
-	Quantity int `json:"quantity" binding:"required"`
+	LocationQuantity int `json:"locationQuantity"`
+	Quantity         int `json:"quantity"`
 }
 
 type FindByIdRequest struct {
 	ID uuid.UUID `json:"bookId" binding:"required"`
 }
+
+// ListBooksRequest - параметры GET /books: курсор, сортировка и фильтры
+type ListBooksRequest struct {
+	After       string `form:"after"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1"`
+	Sort        string `form:"sort"` // например title,-dateOfWriting
+	AuthorID    string `form:"authorId" binding:"omitempty,uuid"`
+	MinQuantity *int   `form:"minQuantity" binding:"omitempty,min=0"`
+	MaxQuantity *int   `form:"maxQuantity" binding:"omitempty,min=0"`
+	WrittenFrom string `form:"writtenFrom" binding:"omitempty,datetime=2006-01-02"`
+	WrittenTo   string `form:"writtenTo" binding:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+const (
+	EditionHardcover   = "hardcover"
+	EditionPaperback   = "paperback"
+	EditionUnspecified = "unspecified"
+)
+
+type Edition struct {
+	ID              uuid.UUID `json:"editionId"`
+	BookID          uuid.UUID `json:"bookId"`
+	Format          string    `json:"format"`
+	Publisher       string    `json:"publisher"`
+	Language        string    `json:"language"`
+	PageCount       *int      `json:"pageCount"`
+	PublicationYear *int      `json:"publicationYear"`
+	OnHand          int       `json:"onHand"` // остаток издания по всем складам
+}
+
+type CreateOrUpdateEditionRequest struct {
+	Format          string `json:"format" binding:"required,oneof=hardcover paperback unspecified"`
+	Publisher       string `json:"publisher" binding:"max=200"`
+	Language        string `json:"language" binding:"omitempty,bcp47_language_tag"`
+	PageCount       *int   `json:"pageCount" binding:"omitempty,min=1,max=100000"`
+	PublicationYear *int   `json:"publicationYear" binding:"omitempty,min=1450,max=9999"`
+}
+
+type CreateEditionResponse struct {
+	ID uuid.UUID `json:"editionId" binding:"required"`
+}
@@ -1,6 +0,0 @@
-package models
-
-type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
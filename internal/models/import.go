@@ -0,0 +1,18 @@
+package models
+
+// ImportReport - итог импорта каталога; при dryRun ничего не записывается, Imported равен нулю
+type ImportReport struct {
+	DryRun         bool             `json:"dryRun"`
+	Total          int              `json:"total"`          // строк с данными в файле
+	Valid          int              `json:"valid"`          // прошли проверку
+	Imported       int              `json:"imported"`       // записаны в БД
+	Failed         int              `json:"failed"`         // не прошли проверку или не записаны
+	AuthorsCreated int              `json:"authorsCreated"` // новых авторов, остальные найдены по ФИО и дате рождения
+	Errors         []ImportRowError `json:"errors"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"` // номер строки в файле, заголовок - строка 1
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
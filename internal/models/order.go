@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OrderDraft     = "draft"
+	OrderPlaced    = "placed"
+	OrderPicking   = "picking"
+	OrderPacked    = "packed"
+	OrderShipped   = "shipped"
+	OrderCancelled = "cancelled"
+)
+
+type Order struct {
+	ID           uuid.UUID   `json:"orderId"`
+	CustomerName string      `json:"customerName"`
+	Comment      string      `json:"comment"`
+	Status       string      `json:"status"`
+	Lines        []OrderLine `json:"lines"`
+	CreatedBy    *uuid.UUID  `json:"createdBy"`
+	CreatedAt    time.Time   `json:"createdAt"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+}
+
+type OrderLine struct {
+	ID            uuid.UUID  `json:"lineId"`
+	BookID        uuid.UUID  `json:"bookId"`
+	Quantity      int        `json:"quantity"`
+	ReservationID *uuid.UUID `json:"reservationId"`
+}
+
+type CreateOrderRequest struct {
+	CustomerName string                   `json:"customerName" binding:"required,max=200"`
+	Comment      string                   `json:"comment" binding:"max=1000"`
+	Lines        []CreateOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+type CreateOrderLineRequest struct {
+	BookID   uuid.UUID `json:"bookId" binding:"required"`
+	Quantity int       `json:"quantity" binding:"required,min=1"`
+}
+
+type ChangeOrderStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=placed picking packed shipped cancelled"`
+}
+
+// OrderTransitionError описывает отклонённую смену статуса заказа
+type OrderTransitionError struct {
+	Status  string   `json:"status"`
+	Target  string   `json:"target"`
+	Allowed []string `json:"allowed"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Supplier struct {
+	ID        uuid.UUID `json:"supplierId"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CreateSupplierRequest struct {
+	Name  string `json:"name" binding:"required,max=200"`
+	Email string `json:"email" binding:"omitempty,email,max=200"`
+	Phone string `json:"phone" binding:"max=50"`
+}
+
+type CreateSupplierResponse struct {
+	ID uuid.UUID `json:"supplierId"`
+}
+
+type PurchaseOrder struct {
+	ID         uuid.UUID           `json:"purchaseOrderId"`
+	Supplier   Supplier            `json:"supplier"`
+	Status     string              `json:"status"`
+	ExpectedAt time.Time           `json:"expectedAt"`
+	Comment    string              `json:"comment"`
+	Lines      []PurchaseOrderLine `json:"lines"`
+	CreatedBy  *uuid.UUID          `json:"createdBy"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+}
+
+type PurchaseOrderLine struct {
+	ID               uuid.UUID `json:"lineId"`
+	BookID           uuid.UUID `json:"bookId"`
+	ExpectedQuantity int       `json:"expectedQuantity"`
+	ReceivedQuantity int       `json:"receivedQuantity"`
+	DamagedQuantity  int       `json:"damagedQuantity"`
+	Outstanding      int       `json:"outstanding"`  // ещё не поставлено
+	OverReceived     int       `json:"overReceived"` // поставлено сверх ожидаемого
+}
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID uuid.UUID                        `json:"supplierId" binding:"required"`
+	ExpectedAt string                           `json:"expectedAt" binding:"required,datetime=2006-01-02"`
+	Comment    string                           `json:"comment" binding:"max=1000"`
+	Lines      []CreatePurchaseOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+type CreatePurchaseOrderLineRequest struct {
+	BookID   uuid.UUID `json:"bookId" binding:"required"`
+	Quantity int       `json:"quantity" binding:"required,min=1"`
+}
+
+type ReceivePurchaseOrderRequest struct {
+	LocationID uuid.UUID                  `json:"locationId" binding:"required"` // ячейка, куда кладутся годные единицы
+	Comment    string                     `json:"comment" binding:"max=1000"`
+	Lines      []ReceivePurchaseOrderLine `json:"lines" binding:"required,min=1,dive"`
+}
+
+type ReceivePurchaseOrderLine struct {
+	BookID    uuid.UUID `json:"bookId" binding:"required"`
+	EditionID uuid.UUID `json:"editionId"` // можно не указывать, если у книги одно издание
+	Received  int       `json:"received" binding:"min=0"`
+	Damaged   int       `json:"damaged" binding:"min=0"`
+}
+
+type PurchaseReceipt struct {
+	ID         uuid.UUID             `json:"receiptId"`
+	LocationID uuid.UUID             `json:"locationId"`
+	ReceivedBy *uuid.UUID            `json:"receivedBy"`
+	Comment    string                `json:"comment"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	Lines      []PurchaseReceiptLine `json:"lines"`
+}
+
+type PurchaseReceiptLine struct {
+	LineID   uuid.UUID `json:"lineId"`
+	Received int       `json:"received"`
+	Damaged  int       `json:"damaged"`
+}
+
+type ReceivePurchaseOrderResponse struct {
+	PurchaseOrder PurchaseOrder   `json:"purchaseOrder"`
+	Receipt       PurchaseReceipt `json:"receipt"`
+}
+
+type PendingPurchaseOrder struct {
+	ID           uuid.UUID `json:"purchaseOrderId"`
+	SupplierID   uuid.UUID `json:"supplierId"`
+	SupplierName string    `json:"supplierName"`
+	Status       string    `json:"status"`
+	ExpectedAt   time.Time `json:"expectedAt"`
+	Outstanding  int       `json:"outstanding"`
+	Overdue      bool      `json:"overdue"`
+	DaysOverdue  int       `json:"daysOverdue"`
+}
+
+type PendingPurchaseOrdersReport struct {
+	AsOf    time.Time              `json:"asOf"`
+	Open    int                    `json:"open"`
+	Overdue int                    `json:"overdue"`
+	Items   []PendingPurchaseOrder `json:"items"`
+}
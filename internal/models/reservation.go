@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Reservation struct {
+	ID        uuid.UUID  `json:"reservationId"`
+	BookID    uuid.UUID  `json:"bookId"`
+	Quantity  int        `json:"quantity"`
+	Status    string     `json:"status"`
+	Reference string     `json:"reference"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	CreatedBy *uuid.UUID `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type CreateReservationRequest struct {
+	BookID    uuid.UUID `json:"bookId" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,min=1"`
+	Reference string    `json:"reference" binding:"max=200"` // номер заказа или иной внешний идентификатор
+}
@@ -0,0 +1,29 @@
+package models
+
+type BookSearchHit struct {
+	Book       Book           `json:"book"`
+	Rank       float64        `json:"rank"`
+	Highlights BookHighlights `json:"highlights"`
+}
+
+// BookHighlights содержит фрагменты с найденными словами, обёрнутыми в <mark>
+type BookHighlights struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+type BookSearchResponse struct {
+	Total int64           `json:"total"`
+	Items []BookSearchHit `json:"items"`
+}
+
+type AuthorSearchHit struct {
+	Author    Author  `json:"author"`
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+type AuthorSearchResponse struct {
+	Total int64             `json:"total"`
+	Items []AuthorSearchHit `json:"items"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actor идентифицирует инициатора изменения остатка для журнала движения
+type Actor struct {
+	UserID        uuid.UUID
+	CorrelationID string
+}
+
+type StockMovement struct {
+	ID              int64      `json:"movementId"`
+	BookID          uuid.UUID  `json:"bookId"`
+	EditionID       uuid.UUID  `json:"editionId"`
+	LocationID      uuid.UUID  `json:"locationId"`
+	Delta           int        `json:"delta"`
+	LocationBalance int        `json:"locationBalance"`
+	Balance         int        `json:"balance"`
+	Reason          string     `json:"reason"`
+	ActorID         *uuid.UUID `json:"actorId"`
+	CorrelationID   string     `json:"correlationId"`
+	Comment         string     `json:"comment"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+type StockMovementsResponse struct {
+	Items []StockMovement `json:"items"`
+	Total int64           `json:"total"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Trash - содержимое корзины: мягко удалённые книги и авторы, последние удалённые первыми
+type Trash struct {
+	Books   []TrashedBook   `json:"books"`
+	Authors []TrashedAuthor `json:"authors"`
+}
+
+type TrashedBook struct {
+	Book      Book       `json:"book"`
+	DeletedAt time.Time  `json:"deletedAt"`
+	DeletedBy *uuid.UUID `json:"deletedBy"` // пусто, если удаливший пользователь уже удалён
+}
+
+type TrashedAuthor struct {
+	Author    Author     `json:"author"`
+	DeletedAt time.Time  `json:"deletedAt"`
+	DeletedBy *uuid.UUID `json:"deletedBy"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RoleViewer  = "viewer"  // только чтение каталога
+	RoleClerk   = "clerk"   // кладовщик: приёмка; только он меняет остатки и перемещает товар между ячейками
+	RoleManager = "manager" // руководитель склада: справочники, закупки, импорт и удаление; остатки напрямую не меняет
+	RoleAdmin   = "admin"   // управление пользователями
+)
+
+type User struct {
+	ID               uuid.UUID  `json:"userId"`
+	Login            string     `json:"login"`
+	Role             string     `json:"role"`
+	Disabled         bool       `json:"disabled"`
+	LastLoginAt      *time.Time `json:"lastLoginAt"`
+	TokensValidAfter *time.Time `json:"-"` // токены, выпущенные раньше, недействительны
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+type CreateUserRequest struct {
+	Login    string `json:"login" binding:"required,min=3,max=100"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Role     string `json:"role" binding:"required,oneof=viewer clerk manager admin"`
+}
+
+type CreateUserResponse struct {
+	ID uuid.UUID `json:"userId" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8,max=72"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"gin_main/pkg/isbn"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// init регистрирует собственные правила проверки в валидаторе gin, чтобы теги binding работали
+// и в обработчиках, и при импорте, где структуры проверяются через binding.Validator напрямую
+func init() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	engine.RegisterTagNameFunc(fieldName)
+	if err := engine.RegisterValidation("isbn", validateISBN); err != nil {
+		panic(err)
+	}
+}
+
+// fieldName называет поле в ошибках проверки так же, как его видит клиент: по тегу json,
+// а для параметров строки запроса - по тегу form. Имя поля структуры остаётся доступным через StructField()
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// validateISBN принимает ISBN-10 и ISBN-13 с дефисами и пробелами, проверяя контрольную цифру
+func validateISBN(field validator.FieldLevel) bool {
+	return isbn.Valid(field.Field().String())
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type Warehouse struct {
+	ID      uuid.UUID `json:"warehouseId"`
+	Code    string    `json:"code"`
+	Name    string    `json:"name"`
+	Address string    `json:"address"`
+}
+
+type CreateWarehouseRequest struct {
+	Code    string `json:"code" binding:"required,min=1,max=50"`
+	Name    string `json:"name" binding:"required,min=1,max=200"`
+	Address string `json:"address" binding:"max=500"`
+}
+
+type CreateWarehouseResponse struct {
+	ID uuid.UUID `json:"warehouseId" binding:"required"`
+}
+
+type Location struct {
+	ID          uuid.UUID `json:"locationId"`
+	WarehouseID uuid.UUID `json:"warehouseId"`
+	Aisle       string    `json:"aisle"`
+	Shelf       string    `json:"shelf"`
+	Bin         string    `json:"bin"`
+}
+
+type CreateLocationRequest struct {
+	Aisle string `json:"aisle" binding:"required,max=50"`
+	Shelf string `json:"shelf" binding:"required,max=50"`
+	Bin   string `json:"bin" binding:"required,max=50"`
+}
+
+type CreateLocationResponse struct {
+	ID uuid.UUID `json:"locationId" binding:"required"`
+}
+
+type StockLevel struct {
+	BookID    uuid.UUID `json:"bookId"`
+	EditionID uuid.UUID `json:"editionId"`
+	Location  Location  `json:"location"`
+	Quantity  int       `json:"quantity"`
+}
+
+type WarehouseStock struct {
+	Warehouse Warehouse    `json:"warehouse"`
+	Quantity  int          `json:"quantity"`
+	Locations []StockLevel `json:"locations"`
+}
+
+type BookStockResponse struct {
+	BookID     uuid.UUID        `json:"bookId"`
+	Quantity   int              `json:"quantity"` // суммарно по всем складам
+	Warehouses []WarehouseStock `json:"warehouses"`
+}
+
+type MoveStockRequest struct {
+	BookID         uuid.UUID `json:"bookId" binding:"required"`
+	EditionID      uuid.UUID `json:"editionId"` // можно не указывать, если у книги одно издание
+	FromLocationID uuid.UUID `json:"fromLocationId" binding:"required"`
+	ToLocationID   uuid.UUID `json:"toLocationId" binding:"required"`
+	Quantity       int       `json:"quantity" binding:"required,min=1"`
+	Comment        string    `json:"comment" binding:"max=1000"`
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"gin_main/internal/repositories/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrAuthorHasBooks = errors.New("author has books")
+
+type AuthorRepositoryInterface interface {
+	Create(ctx context.Context, author entities.Author) (entities.Author, error)  // создаёт автора и возвращает созданный объект
+	Update(ctx context.Context, author entities.Author, version int) (int, error) // изменяет автора, если его версия равна version (0 - без проверки), и возвращает новую версию
+	FindById(ctx context.Context, id uuid.UUID) (entities.Author, error)          // найдёт автора по конкретному id
+	GetAll(ctx context.Context) ([]entities.Author, error)                        // возвращает всех авторов
+	Delete(ctx context.Context, id, deletedBy uuid.UUID) error                    // переносит автора в корзину, если он не участвует ни в одной книге вне корзины
+	GetBooks(ctx context.Context, id uuid.UUID) ([]entities.Book, error)          // возвращает все книги, в которых автор участвует в любой роли
+}
+
+type authorRepository struct {
+	database *gorm.DB
+}
+
+func NewAuthorRepository(database *gorm.DB) AuthorRepositoryInterface {
+	return &authorRepository{database: database}
+}
+
+func (r *authorRepository) Create(ctx context.Context, author entities.Author) (entities.Author, error) {
+	if author.ID == uuid.Nil {
+		author.ID = uuid.New()
+	}
+	if result := r.database.WithContext(ctx).Create(&author); result.Error != nil {
+		return entities.Author{}, result.Error
+	}
+	return author, nil
+}
+
+func (r *authorRepository) Update(ctx context.Context, author entities.Author, version int) (int, error) {
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if author.Version, err = nextVersion(tx, &entities.Author{}, author.ID, version); err != nil {
+			return err
+		}
+		// Select("*"): без него Updates пропускает нулевые значения и, например, не очищает отчество
+		return tx.Model(&author).Select("*").Omit("id", "deleted_at", "deleted_by").Updates(&author).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return author.Version, nil
+}
+
+func (r *authorRepository) FindById(ctx context.Context, id uuid.UUID) (entities.Author, error) {
+	var author entities.Author
+	if result := r.database.WithContext(ctx).First(&author, "id = ?", id); result.Error != nil {
+		return entities.Author{}, result.Error
+	}
+	return author, nil
+}
+
+func (r *authorRepository) GetAll(ctx context.Context) ([]entities.Author, error) {
+	var authors []entities.Author
+	if results := r.database.WithContext(ctx).Order("surname, first_name, second_name").Find(&authors); results.Error != nil {
+		return nil, results.Error
+	}
+	return authors, nil
+}
+
+func (r *authorRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	return r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// блокировка автора конфликтует с SHARE-блокировкой, которую берут запись книги и импорт:
+		// пока идёт проверка, никто не добавит автору живую книгу
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&entities.Author{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		var booksCount int64
+		err := tx.Model(&entities.BookContributor{}).
+			Joins("join books on books.id = book_contributors.book_id and books.deleted_at is null").
+			Where("book_contributors.author_id = ?", id).
+			Count(&booksCount).Error
+		if err != nil {
+			return err
+		}
+		if booksCount > 0 {
+			return ErrAuthorHasBooks
+		}
+		return softDelete(tx, &entities.Author{}, id, deletedBy)
+	})
+}
+
+func (r *authorRepository) GetBooks(ctx context.Context, id uuid.UUID) ([]entities.Book, error) {
+	var books []entities.Book
+	if results := r.database.WithContext(ctx).Scopes(withStock, withDetails).
+		Where("exists (select 1 from book_contributors bc where bc.book_id = books.id and bc.author_id = ?)", id).
+		Order("books.title").
+		Find(&books); results.Error != nil {
+		return nil, results.Error
+	}
+	return books, nil
+}
@@ -1,24 +1,95 @@
 package repositories
 
 import (
-	"fmt"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/pagination"
+	"gin_main/pkg/textsearch"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookRepositoryInterface interface {
-	Create(book entities.Book) (entities.Book, error)                                                      // создаёт книгу и возвращает ID или созданный объект книги
-	Update(book entities.Book) error                                                                       // изменяет конкретную книгу
-	FindById(id uuid.UUID) (entities.Book, error)                                                          // найдёт книгу по конкретному id
-	FindByParameters(title, author string, yearOfWriting, yearOfBirth *time.Time) ([]entities.Book, error) // найдёт по параметрам (автор, название, год) | мне могут передать ФИО полностью, ФИО с инициалами, только фамилию или год рождения или год написания
-	GetAll() ([]entities.Book, error)                                                                      // возвращает все книги, должен возвращать потоком
-	ChangeQuantity(id uuid.UUID, quantity int) (int, error)                                                // изменяет количество остатка для книги по id
+	Create(ctx context.Context, book entities.Book) (entities.Book, error)                                                      // создаёт книгу с участниками и изданиями (без изданий заводит одно неописанное) и возвращает её
+	Update(ctx context.Context, book entities.Book, version int) (int, error)                                                   // изменяет книгу, если её версия равна version (0 - без проверки); если Contributors не nil, заменяет состав участников; возвращает новую версию
+	FindById(ctx context.Context, id uuid.UUID) (entities.Book, error)                                                          // найдёт книгу по конкретному id
+	FindByISBN(ctx context.Context, isbn string) (entities.Book, error)                                                         // найдёт книгу по нормализованному ISBN-13
+	FindByParameters(ctx context.Context, title, author string, yearOfWriting, yearOfBirth *time.Time) ([]entities.Book, error) // найдёт по параметрам (автор, название, год) | мне могут передать ФИО полностью, ФИО с инициалами, только фамилию или год рождения или год написания
+	List(ctx context.Context, filter BookFilter, page pagination.Request) ([]entities.Book, error)                              // возвращает страницу книг по курсору, до page.FetchLimit() строк
+	Export(ctx context.Context, visit func(entities.Book) error) error                                                          // построчно читает весь каталог курсором БД, не держа его в памяти
+	ChangeQuantity(ctx context.Context, id, editionID, locationID uuid.UUID, quantity int, info MovementInfo) (int, int, error) // изменяет остаток издания в ячейке с записью в журнал, возвращает остаток в ячейке и общий остаток книги; uuid.Nil вместо издания означает единственное издание книги
+	Delete(ctx context.Context, id, deletedBy uuid.UUID) error                                                                  // переносит книгу в корзину, если по ней нет остатка и активных резервов
+	StockSummary(ctx context.Context) (StockSummary, error)                                                                     // общий остаток и число книг без остатка по каталогу без корзины
 }
 
+var (
+	ErrISBNExists      = errors.New("book with this isbn already exists")
+	ErrEditionRequired = errors.New("book has several editions, edition must be specified")
+	ErrBookHasStock    = errors.New("book has stock on hand")
+	ErrBookReserved    = errors.New("book has active reservations")
+	ErrVersionMismatch = errors.New("record version does not match")
+	ErrNegativeStock   = errors.New("quantity cannot be negative")
+)
+
+// BookFilter - необязательные фильтры списка книг; nil означает "без ограничения"
+type BookFilter struct {
+	AuthorID    *uuid.UUID
+	MinQuantity *int
+	MaxQuantity *int
+	WrittenFrom *time.Time
+	WrittenTo   *time.Time
+}
+
+// StockSummary - сводка остатков по каталогу для метрик
+type StockSummary struct {
+	Units          int64 // экземпляров на всех складах
+	ZeroStockBooks int64 // книг, которых нет ни в одной ячейке
+}
+
+// BookListSpec задаёт поля сортировки списка книг; колонки совпадают с выборкой withStock
+var BookListSpec = pagination.Spec{
+	Fields: map[string]pagination.Field{
+		"id":            {Column: "books.id", Kind: pagination.KindUUID},
+		"title":         {Column: "books.title", Kind: pagination.KindString},
+		"dateOfWriting": {Column: "coalesce(books.date_of_writing, '0001-01-01')", Kind: pagination.KindTime},
+		"quantity":      {Column: "st.on_hand", Kind: pagination.KindInt},
+		"available":     {Column: "(st.on_hand - st.reserved)", Kind: pagination.KindInt},
+	},
+	Tiebreaker:   "id",
+	DefaultSort:  "title",
+	DefaultLimit: 50,
+	MaxLimit:     500,
+}
+
+// BookSortValue возвращает значение ключа сортировки книги для курсора следующей страницы
+func BookSortValue(book entities.Book, name string) any {
+	switch name {
+	case "title":
+		return book.Title
+	case "dateOfWriting":
+		return book.DateOfWriting
+	case "quantity":
+		return book.OnHand
+	case "available":
+		return book.Available
+	default:
+		return book.ID
+	}
+}
+
+const upsertStockLevel = `insert into stock_levels (book_id, edition_id, location_id, quantity) values (?, ?, ?, ?)
+on conflict (edition_id, location_id) do update set quantity = stock_levels.quantity + excluded.quantity
+returning quantity`
+
 type bookRepository struct {
 	database *gorm.DB
 }
@@ -27,88 +98,405 @@ func NewBookRepository(database *gorm.DB) BookRepositoryInterface {
 	return &bookRepository{database: database}
 }
 
-func (r *bookRepository) Create(book entities.Book) (entities.Book, error) {
+func (r *bookRepository) Create(ctx context.Context, book entities.Book) (entities.Book, error) {
+	if book.ID == uuid.Nil {
+		book.ID = uuid.New()
+	}
 	book.Title = strings.ToTitle(book.Title)
-	if result := r.database.Create(&book); result.Error != nil {
-		return entities.Book{}, result.Error
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(&book).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrISBNExists
+			}
+			return err
+		}
+		if err := saveContributors(tx, book.ID, book.Contributors); err != nil {
+			return err
+		}
+		if len(book.Editions) == 0 {
+			book.Editions = []entities.Edition{{Format: entities.EditionUnspecified}}
+		}
+		for i := range book.Editions {
+			book.Editions[i].ID = uuid.New()
+			book.Editions[i].BookID = book.ID
+		}
+		return tx.Create(&book.Editions).Error
+	})
+	if err != nil {
+		return entities.Book{}, err
 	}
 	return book, nil
 }
 
-func (r *bookRepository) Update(book entities.Book) error {
-	if result := r.database.Updates(&book); result.Error != nil {
-		return result.Error
+func (r *bookRepository) Update(ctx context.Context, book entities.Book, version int) (int, error) {
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if book.Version, err = nextVersion(tx, &entities.Book{}, book.ID, version); err != nil {
+			return err
+		}
+		// Select("*"): без него Updates пропускает нулевые значения, и полное обновление не могло бы очистить ISBN
+		if err := tx.Model(&book).Select("*").Omit("id", "deleted_at", "deleted_by", clause.Associations).Updates(&book).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrISBNExists
+			}
+			return err
+		}
+		if book.Contributors == nil {
+			return nil
+		}
+		if err := tx.Where("book_id = ?", book.ID).Delete(&entities.BookContributor{}).Error; err != nil {
+			return err
+		}
+		return saveContributors(tx, book.ID, book.Contributors)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return book.Version, nil
+}
+
+func (r *bookRepository) FindById(ctx context.Context, id uuid.UUID) (entities.Book, error) {
+	var book entities.Book
+	if result := r.database.WithContext(ctx).Scopes(withStock, withDetails).First(&book, "books.id = ?", id); result.Error != nil {
+		return entities.Book{}, result.Error
 	}
-	return nil
+	return book, nil
 }
 
-func (r *bookRepository) FindById(id uuid.UUID) (entities.Book, error) {
+func (r *bookRepository) FindByISBN(ctx context.Context, isbn string) (entities.Book, error) {
 	var book entities.Book
-	if result := r.database.Preload("author").Find(&book, id); result != nil {
+	if result := r.database.WithContext(ctx).Scopes(withStock, withDetails).First(&book, "books.isbn = ?", isbn); result.Error != nil {
 		return entities.Book{}, result.Error
 	}
 	return book, nil
 }
 
-func (r *bookRepository) FindByParameters(title, author string, yearOfWriting, yearOfBirth *time.Time) ([]entities.Book, error) {
+func (r *bookRepository) FindByParameters(ctx context.Context, title, author string, yearOfWriting, yearOfBirth *time.Time) ([]entities.Book, error) {
 	var books []entities.Book
-	query := r.database.Model(&entities.Book{}).Joins("join authors a on a.id = books.author_id")
+	query := r.database.WithContext(ctx).Model(&entities.Book{})
 	if title != "" {
-		query = query.Where("title ilike ?", title)
+		query = query.Where("books.title ilike ?", title)
 	}
-	//NOTE: пока использую поиск в тупую с ограничением юзера, в будущем (Postgres full-text search)
-	if author != "" {
-		// ищем по ФИО полным или Фамилия инициалы или просто по Фамилии
-		words := strings.Split(author, " ")
-		if len(words) == 1 { // только фамилия
-			query = query.Where("a.surname ilike ?", words[0])
+	if author != "" || yearOfBirth != nil {
+		// условия на автора должны выполняться для одного и того же участника книги
+		contributor := r.database.WithContext(ctx).Table("book_contributors bc").
+			Select("1").
+			Joins("join authors a on a.id = bc.author_id").
+			Where("bc.book_id = books.id")
+		if author != "" {
+			// ФИО в любом порядке, "Фамилия И.О." или только фамилия: каждое слово совпадает с одной из частей ФИО,
+			// инициалы по порядку с именем и отчеством
+			parsed := textsearch.Parse(author)
+			for _, word := range parsed.Words {
+				contributor = contributor.Where("(a.surname ilike ? or a.first_name ilike ? or a.second_name ilike ?)", word, word, word)
+			}
+			contributor = contributor.Scopes(initialsFilter(parsed))
 		}
-		if len(words) == 3 { // фамилия имя отчество
-			query = query.Where("a.surname ilike ? and a.first_name ilike ? and a.second_name ilike ?)", words[0], "%"+words[1]+"%", "%"+words[2]+"%")
+		if yearOfBirth != nil {
+			contributor = contributor.Where("a.date_of_birth = ?", *yearOfBirth)
 		}
-		// if len(words) == 2 { // фамилия инициалы
-		// 	query = query.Where("author_id = (select id from author where surname ilike ?)", words[0])
-		// }
-		//query = query.Where("author_id = (select id from author where surname ilike ?)", author)
+		query = query.Where("exists (?)", contributor)
 	}
 	if yearOfWriting != nil {
-		query = query.Where("yearOfWriting = ?", *yearOfWriting)
-	}
-	if yearOfBirth != nil {
-		query = query.Where("yearOfBirth = ?", *yearOfBirth)
+		query = query.Where("books.date_of_writing = ?", *yearOfWriting)
 	}
-	if results := query.Preload("Author").Find(&books); results != nil {
+	if results := query.Scopes(withStock, withDetails).Find(&books); results.Error != nil {
 		return nil, results.Error
 	}
 	return books, nil
 }
 
-func (r *bookRepository) GetAll() ([]entities.Book, error) {
+func (r *bookRepository) List(ctx context.Context, filter BookFilter, page pagination.Request) ([]entities.Book, error) {
 	var books []entities.Book
-	if results := r.database.Preload("author").Find(&books); results.Error != nil {
+	query := r.database.WithContext(ctx).Scopes(withStock, withDetails)
+	if filter.AuthorID != nil {
+		query = query.Where("exists (select 1 from book_contributors bc where bc.book_id = books.id and bc.author_id = ?)", *filter.AuthorID)
+	}
+	if filter.MinQuantity != nil {
+		query = query.Where("st.on_hand >= ?", *filter.MinQuantity)
+	}
+	if filter.MaxQuantity != nil {
+		query = query.Where("st.on_hand <= ?", *filter.MaxQuantity)
+	}
+	if filter.WrittenFrom != nil {
+		query = query.Where("books.date_of_writing >= ?", *filter.WrittenFrom)
+	}
+	if filter.WrittenTo != nil {
+		query = query.Where("books.date_of_writing <= ?", *filter.WrittenTo)
+	}
+	if condition, args := page.Condition(); condition != "" {
+		query = query.Where(condition, args...)
+	}
+	if results := query.Order(page.OrderBy()).Limit(page.FetchLimit()).Find(&books); results.Error != nil {
 		return nil, results.Error
 	}
 	return books, nil
 }
 
-func (r *bookRepository) ChangeQuantity(id uuid.UUID, quantity int) (int, error) {
-	var newQuantity int
-	err := r.database.Transaction(func(tx *gorm.DB) error {
-		var current int
-		if err := tx.Table("book").Select("quantity").Where("id = ?", id).Scan(&current).Error; err != nil {
+func (r *bookRepository) Export(ctx context.Context, visit func(entities.Book) error) error {
+	rows, err := r.database.WithContext(ctx).
+		Table("books").
+		Select("books.id, books.title, books.isbn, books.date_of_writing, ct.contributors, st.on_hand, st.reserved").
+		Joins("cross join lateral (" + contributorsJSONSubquery + ") ct").
+		Joins("cross join lateral (" + stockSubquery + ") st").
+		Where("books.deleted_at is null").
+		Order("books.id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book entities.Book
+		var dateOfWriting sql.NullTime
+		var contributors []byte
+		err := rows.Scan(&book.ID, &book.Title, &book.ISBN, &dateOfWriting, &contributors, &book.OnHand, &book.Reserved)
+		if err != nil {
 			return err
 		}
-		if current+quantity < 0 {
-			return fmt.Errorf("quantity cannot be negative") // TODO: создать отдельный файл с ошибками
+		book.DateOfWriting = dateOfWriting.Time
+		if book.Contributors, err = decodeContributors(book.ID, contributors); err != nil {
+			return err
 		}
-		if err := tx.Exec("update book set quantity = quantity + ? where id = ?", quantity, id).Error; err != nil {
+		book.Available = book.OnHand - book.Reserved
+		if err := visit(book); err != nil {
 			return err
 		}
-		newQuantity = current + quantity
-		return nil
+	}
+	return rows.Err()
+}
+
+func (r *bookRepository) ChangeQuantity(ctx context.Context, id, editionID, locationID uuid.UUID, quantity int, info MovementInfo) (int, int, error) {
+	var locationQuantity, totalQuantity int
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		editionID, err := resolveEdition(tx, id, editionID)
+		if err != nil {
+			return err
+		}
+		if quantity < 0 {
+			// списание не должно затрагивать остаток, удерживаемый активными резервами
+			onHand, reserved, err := lockBookStock(tx, id)
+			if err != nil {
+				return err
+			}
+			if onHand+quantity < reserved {
+				return ErrStockReserved
+			}
+		}
+		if locationQuantity, err = changeStockLevel(tx, id, editionID, locationID, quantity, info); err != nil {
+			return err
+		}
+		return tx.Model(&entities.StockLevel{}).Select("coalesce(sum(quantity), 0)").Where("book_id = ?", id).Scan(&totalQuantity).Error
 	})
 	if err != nil {
+		return 0, 0, err
+	}
+	return locationQuantity, totalQuantity, nil
+}
+
+func (r *bookRepository) StockSummary(ctx context.Context) (StockSummary, error) {
+	var summary StockSummary
+	err := r.database.WithContext(ctx).Raw(stockSummaryQuery).Scan(&summary).Error
+	return summary, err
+}
+
+const stockSummaryQuery = `select coalesce(sum(st.quantity), 0) as units,
+	count(*) filter (where coalesce(st.quantity, 0) = 0) as zero_stock_books
+from books
+left join (select book_id, sum(quantity) as quantity from stock_levels group by book_id) st on st.book_id = books.id
+where books.deleted_at is null`
+
+func (r *bookRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	return r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// блокировка книги не даёт параллельно оприходовать или зарезервировать её, пока она уходит в корзину
+		onHand, reserved, err := lockBookStock(tx, id)
+		if err != nil {
+			return err
+		}
+		if onHand > 0 {
+			return ErrBookHasStock
+		}
+		if reserved > 0 {
+			return ErrBookReserved
+		}
+		return softDelete(tx, &entities.Book{}, id, deletedBy)
+	})
+}
+
+// nextVersion блокирует запись до конца транзакции и сверяет её версию с ожидаемой (0 - без проверки);
+// возвращает версию, которую нужно записать вместе с изменением
+func nextVersion(tx *gorm.DB, model any, id uuid.UUID, version int) (int, error) {
+	var versions []int
+	err := tx.Model(model).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		Pluck("version", &versions).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if version != 0 && versions[0] != version {
+		return 0, ErrVersionMismatch
+	}
+	return versions[0] + 1, nil
+}
+
+// changeStockLevel меняет остаток издания в ячейке на delta и пишет строку журнала в той же транзакции
+func changeStockLevel(tx *gorm.DB, bookID, editionID, locationID uuid.UUID, delta int, info MovementInfo) (int, error) {
+	var current entities.StockLevel
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("edition_id = ? and location_id = ?", editionID, locationID).
+		Take(&current).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return 0, err
 	}
-	return newQuantity, nil
+	if current.Quantity+delta < 0 {
+		return 0, ErrNegativeStock
+	}
+	var locationQuantity int
+	if err := tx.Raw(upsertStockLevel, bookID, editionID, locationID, delta).Scan(&locationQuantity).Error; err != nil {
+		return 0, err
+	}
+	if err := recordMovement(tx, bookID, editionID, locationID, delta, locationQuantity, info); err != nil {
+		return 0, err
+	}
+	return locationQuantity, nil
 }
+
+// resolveEdition проверяет, что издание относится к книге; без явного издания берётся единственное издание книги
+func resolveEdition(tx *gorm.DB, bookID, editionID uuid.UUID) (uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := tx.Model(&entities.Edition{}).Where("book_id = ?", bookID).Where(editionOfLiveBook)
+	if editionID != uuid.Nil {
+		query = query.Where("id = ?", editionID)
+	}
+	if err := query.Limit(2).Pluck("id", &ids).Error; err != nil {
+		return uuid.Nil, err
+	}
+	switch len(ids) {
+	case 0:
+		return uuid.Nil, gorm.ErrRecordNotFound
+	case 1:
+		return ids[0], nil
+	default:
+		return uuid.Nil, ErrEditionRequired
+	}
+}
+
+// saveContributors заводит авторов, переданных без id, и записывает участников книги в порядке перечисления
+func saveContributors(tx *gorm.DB, bookID uuid.UUID, contributors []entities.BookContributor) error {
+	if len(contributors) == 0 {
+		return nil
+	}
+	if err := lockLiveAuthors(tx, contributors); err != nil {
+		return err
+	}
+	authorRepo := NewAuthorRepository(tx)
+	for i := range contributors {
+		if contributors[i].AuthorID == uuid.Nil {
+			author, err := authorRepo.Create(tx.Statement.Context, contributors[i].Author)
+			if err != nil {
+				return err
+			}
+			contributors[i].Author = author
+			contributors[i].AuthorID = author.ID
+		}
+		contributors[i].BookID = bookID
+		contributors[i].Position = i
+	}
+	return tx.Omit("Author").Create(&contributors).Error
+}
+
+// lockLiveAuthors проверяет, что указанные по id авторы существуют и не в корзине, и не даёт удалить их до конца транзакции;
+// внешний ключ этого не гарантирует, потому что строка удалённого автора остаётся в таблице
+func lockLiveAuthors(tx *gorm.DB, contributors []entities.BookContributor) error {
+	ids := make(map[uuid.UUID]struct{}, len(contributors))
+	for _, contributor := range contributors {
+		if contributor.AuthorID != uuid.Nil {
+			ids[contributor.AuthorID] = struct{}{}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var found []uuid.UUID
+	err := tx.Model(&entities.Author{}).
+		Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("id in ?", slices.Collect(maps.Keys(ids))).
+		Pluck("id", &found).Error
+	if err != nil {
+		return err
+	}
+	if len(found) != len(ids) {
+		return gorm.ErrForeignKeyViolated
+	}
+	return nil
+}
+
+// withDetails подгружает участников книги в порядке перечисления и её издания с остатком каждого
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Contributors", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Contributors.Author").
+		Preload("Editions", withEditionStock)
+}
+
+// contributorsJSONSubquery собирает участников книги одной строкой JSON, чтобы экспорт читал каталог одним запросом
+const contributorsJSONSubquery = `select coalesce(json_agg(json_build_object(
+		'authorId', a.id, 'role', bc.role, 'surname', a.surname, 'firstName', a.first_name,
+		'secondName', a.second_name, 'dateOfBirth', to_char(a.date_of_birth, 'YYYY-MM-DD')
+	) order by bc.position), '[]') as contributors
+	from book_contributors bc join authors a on a.id = bc.author_id
+	where bc.book_id = books.id`
+
+func decodeContributors(bookID uuid.UUID, raw []byte) ([]entities.BookContributor, error) {
+	var rows []struct {
+		AuthorID    uuid.UUID `json:"authorId"`
+		Role        string    `json:"role"`
+		Surname     string    `json:"surname"`
+		FirstName   string    `json:"firstName"`
+		SecondName  string    `json:"secondName"`
+		DateOfBirth *string   `json:"dateOfBirth"`
+	}
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, err
+	}
+	contributors := make([]entities.BookContributor, 0, len(rows))
+	for i, row := range rows {
+		contributor := entities.BookContributor{
+			BookID:   bookID,
+			AuthorID: row.AuthorID,
+			Role:     row.Role,
+			Position: i,
+			Author: entities.Author{
+				ID:         row.AuthorID,
+				Surname:    row.Surname,
+				FirstName:  row.FirstName,
+				SecondName: row.SecondName,
+			},
+		}
+		if row.DateOfBirth != nil {
+			dateOfBirth, err := time.Parse(time.DateOnly, *row.DateOfBirth)
+			if err != nil {
+				return nil, err
+			}
+			contributor.Author.DateOfBirth = dateOfBirth
+		}
+		contributors = append(contributors, contributor)
+	}
+	return contributors, nil
+}
+
+// withStock добавляет к выборке книг остаток по всем ячейкам, объём активных резервов и доступное количество
+func withStock(db *gorm.DB) *gorm.DB {
+	return db.Select("books.*, st.on_hand, st.reserved, st.on_hand - st.reserved as available").
+		Joins("cross join lateral (" + stockSubquery + ") st")
+}
+
+const stockSubquery = `select
+	(select coalesce(sum(s.quantity), 0) from stock_levels s where s.book_id = books.id) as on_hand,
+	(select coalesce(sum(r.quantity), 0) from reservations r
+		where r.book_id = books.id and r.status = 'active' and r.expires_at > now()) as reserved`
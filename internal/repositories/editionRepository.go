@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"gin_main/internal/repositories/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEditionHasStock = errors.New("edition has stock or stock history")
+	ErrLastEdition     = errors.New("book must keep at least one edition")
+)
+
+// editionOfLiveBook отсекает издания книг, удалённых в корзину
+const editionOfLiveBook = "exists (select 1 from books where books.id = editions.book_id and books.deleted_at is null)"
+
+type EditionRepositoryInterface interface {
+	Create(ctx context.Context, edition entities.Edition) (entities.Edition, error) // добавляет издание книги
+	Update(ctx context.Context, edition entities.Edition) error                     // заменяет описание издания целиком
+	FindById(ctx context.Context, bookID, id uuid.UUID) (entities.Edition, error)   // найдёт издание книги вместе с остатком
+	GetByBook(ctx context.Context, bookID uuid.UUID) ([]entities.Edition, error)    // возвращает издания книги с остатками, книга должна существовать
+	Delete(ctx context.Context, bookID, id uuid.UUID) error                         // удаляет издание, по которому никогда не было остатка, если оно не последнее
+}
+
+type editionRepository struct {
+	database *gorm.DB
+}
+
+func NewEditionRepository(database *gorm.DB) EditionRepositoryInterface {
+	return &editionRepository{database: database}
+}
+
+func (r *editionRepository) Create(ctx context.Context, edition entities.Edition) (entities.Edition, error) {
+	if edition.ID == uuid.Nil {
+		edition.ID = uuid.New()
+	}
+	// внешний ключ не отличает книгу в корзине от живой
+	if err := r.database.WithContext(ctx).Select("id").First(&entities.Book{}, "id = ?", edition.BookID).Error; err != nil {
+		return entities.Edition{}, err
+	}
+	if result := r.database.WithContext(ctx).Create(&edition); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return entities.Edition{}, gorm.ErrRecordNotFound
+		}
+		return entities.Edition{}, result.Error
+	}
+	return edition, nil
+}
+
+func (r *editionRepository) Update(ctx context.Context, edition entities.Edition) error {
+	result := r.database.WithContext(ctx).Model(&edition).
+		Where("book_id = ?", edition.BookID).
+		Where(editionOfLiveBook).
+		Select("format", "publisher", "language", "page_count", "publication_year").
+		Updates(&edition)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *editionRepository) FindById(ctx context.Context, bookID, id uuid.UUID) (entities.Edition, error) {
+	var edition entities.Edition
+	if result := r.database.WithContext(ctx).Scopes(withEditionStock).Where(editionOfLiveBook).First(&edition, "id = ? and book_id = ?", id, bookID); result.Error != nil {
+		return entities.Edition{}, result.Error
+	}
+	return edition, nil
+}
+
+func (r *editionRepository) GetByBook(ctx context.Context, bookID uuid.UUID) ([]entities.Edition, error) {
+	if err := r.database.WithContext(ctx).Select("id").First(&entities.Book{}, "id = ?", bookID).Error; err != nil {
+		return nil, err
+	}
+	var editions []entities.Edition
+	if results := r.database.WithContext(ctx).Scopes(withEditionStock).Where("book_id = ?", bookID).Find(&editions); results.Error != nil {
+		return nil, results.Error
+	}
+	return editions, nil
+}
+
+func (r *editionRepository) Delete(ctx context.Context, bookID, id uuid.UUID) error {
+	return r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// книга блокируется, чтобы два параллельных удаления не оставили её без изданий
+		if _, _, err := lockBookStock(tx, bookID); err != nil {
+			return err
+		}
+		var editionsCount int64
+		if err := tx.Model(&entities.Edition{}).Where("book_id = ?", bookID).Count(&editionsCount).Error; err != nil {
+			return err
+		}
+		result := tx.Where("book_id = ?", bookID).Delete(&entities.Edition{}, "id = ?", id)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+				return ErrEditionHasStock
+			}
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if editionsCount <= 1 {
+			return ErrLastEdition
+		}
+		return nil
+	})
+}
+
+// withEditionStock добавляет к выборке изданий их остаток по всем ячейкам
+func withEditionStock(db *gorm.DB) *gorm.DB {
+	return db.Select("editions.*, (select coalesce(sum(s.quantity), 0) from stock_levels s where s.edition_id = editions.id) as on_hand").
+		Order("created_at, id")
+}
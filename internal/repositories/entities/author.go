@@ -4,12 +4,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Author struct {
-	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
-	DateOfBirth time.Time `gorm:"type:date"`
-	FirstName   string    `gorm:"type:text"`
-	SecondName  string    `gorm:"type:text"`
-	Surname     string    `gorm:"type:text"`
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	DateOfBirth time.Time      `gorm:"type:date"`
+	FirstName   string         `gorm:"type:text"`
+	SecondName  string         `gorm:"type:text"`
+	Surname     string         `gorm:"type:text"`
+	Version     int            `gorm:"type:int;default:1"` // номер версии для оптимистической блокировки
+	DeletedAt   gorm.DeletedAt `gorm:"type:timestamptz"`   // автор в корзине; gorm исключает такие записи из обычных запросов
+	DeletedBy   *uuid.UUID     `gorm:"type:uuid"`
 }
@@ -4,13 +4,55 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ContributorAuthor      = "author"
+	ContributorTranslator  = "translator"
+	ContributorEditor      = "editor"
+	ContributorIllustrator = "illustrator"
+)
+
+const (
+	EditionHardcover   = "hardcover"
+	EditionPaperback   = "paperback"
+	EditionUnspecified = "unspecified" // формат не указан; так заведены издания книг, существовавших до разделения на издания
 )
 
 type Book struct {
-	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
-	DateOfWriting time.Time `gorm:"type:date"`
-	Title         string    `gorm:"type:text"`
-	AuthorID      uuid.UUID `gorm:"type:uuid"`
-	Quantity      int       `gorm:"type:int"`
-	Author        Author
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	DateOfWriting time.Time      `gorm:"type:date"`
+	Title         string         `gorm:"type:text"`
+	ISBN          *string        `gorm:"column:isbn;type:text"` // ISBN-13 без дефисов, уникален среди заданных
+	OnHand        int            `gorm:"->;-:migration"`        // сумма stock_levels по всем ячейкам и изданиям, только для чтения
+	Reserved      int            `gorm:"->;-:migration"`        // сумма активных резервов
+	Available     int            `gorm:"->;-:migration"`        // OnHand - Reserved
+	Version       int            `gorm:"type:int;default:1"`    // номер версии для оптимистической блокировки
+	DeletedAt     gorm.DeletedAt `gorm:"type:timestamptz"`      // книга в корзине; gorm исключает такие записи из обычных запросов
+	DeletedBy     *uuid.UUID     `gorm:"type:uuid"`
+	Contributors  []BookContributor
+	Editions      []Edition
+}
+
+// BookContributor связывает книгу с автором в определённой роли; один автор может быть, например, и автором, и переводчиком
+type BookContributor struct {
+	BookID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	AuthorID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Role     string    `gorm:"type:text;primaryKey"`
+	Position int       `gorm:"type:int"`
+	Author   Author
+}
+
+// Edition - физическое издание книги; остатки на складе ведутся по изданиям
+type Edition struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BookID          uuid.UUID `gorm:"type:uuid"`
+	Format          string    `gorm:"type:text"`
+	Publisher       string    `gorm:"type:text"`
+	Language        string    `gorm:"type:text"`
+	PageCount       *int      `gorm:"type:int"`
+	PublicationYear *int      `gorm:"type:int"`
+	CreatedAt       time.Time `gorm:"type:timestamptz"`
+	OnHand          int       `gorm:"->;-:migration"` // сумма stock_levels издания по всем ячейкам
 }
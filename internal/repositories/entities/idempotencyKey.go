@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+)
+
+type IdempotencyKey struct {
+	Scope        string    `gorm:"type:text;primaryKey"`
+	Key          string    `gorm:"type:text;primaryKey"`
+	RequestHash  string    `gorm:"type:text"`
+	Completed    bool      `gorm:"type:boolean"`
+	StatusCode   int       `gorm:"type:int"`
+	ContentType  string    `gorm:"type:text"`
+	ResponseBody []byte    `gorm:"type:bytea"`
+	CreatedAt    time.Time `gorm:"type:timestamptz"`
+	ExpiresAt    time.Time `gorm:"type:timestamptz"`
+}
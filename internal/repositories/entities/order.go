@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OrderDraft     = "draft"
+	OrderPlaced    = "placed"
+	OrderPicking   = "picking"
+	OrderPacked    = "packed"
+	OrderShipped   = "shipped"
+	OrderCancelled = "cancelled"
+)
+
+type Order struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	CustomerName string     `gorm:"type:text"`
+	Comment      string     `gorm:"type:text"`
+	Status       string     `gorm:"type:text"`
+	CreatedBy    *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt    time.Time  `gorm:"type:timestamptz"`
+	UpdatedAt    time.Time  `gorm:"type:timestamptz"`
+	Lines        []OrderLine
+}
+
+type OrderLine struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrderID       uuid.UUID  `gorm:"type:uuid"`
+	BookID        uuid.UUID  `gorm:"type:uuid"`
+	Quantity      int        `gorm:"type:int"`
+	ReservationID *uuid.UUID `gorm:"type:uuid"`
+}
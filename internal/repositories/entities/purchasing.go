@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PurchaseOrderOpen     = "open"
+	PurchaseOrderPartial  = "partial"
+	PurchaseOrderReceived = "received"
+	PurchaseOrderClosed   = "closed"
+)
+
+type Supplier struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name      string    `gorm:"type:text"`
+	Email     string    `gorm:"type:text"`
+	Phone     string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"type:timestamptz"`
+}
+
+type PurchaseOrder struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	SupplierID uuid.UUID  `gorm:"type:uuid"`
+	Status     string     `gorm:"type:text"`
+	ExpectedAt time.Time  `gorm:"type:date"`
+	Comment    string     `gorm:"type:text"`
+	CreatedBy  *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz"`
+	UpdatedAt  time.Time  `gorm:"type:timestamptz"`
+	Supplier   Supplier
+	Lines      []PurchaseOrderLine
+}
+
+type PurchaseOrderLine struct {
+	ID               uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PurchaseOrderID  uuid.UUID `gorm:"type:uuid"`
+	BookID           uuid.UUID `gorm:"type:uuid"`
+	ExpectedQuantity int       `gorm:"type:int"`
+	ReceivedQuantity int       `gorm:"type:int"`
+	DamagedQuantity  int       `gorm:"type:int"`
+}
+
+// Outstanding возвращает, сколько единиц ещё не поставлено; повреждённые считаются поставленными
+func (l PurchaseOrderLine) Outstanding() int {
+	return max(l.ExpectedQuantity-l.ReceivedQuantity-l.DamagedQuantity, 0)
+}
+
+// StatusAfterReceipt - статус заказа после приёмки: получен, когда не осталось недопоставленных строк
+func StatusAfterReceipt(lines []PurchaseOrderLine) string {
+	for _, line := range lines {
+		if line.Outstanding() > 0 {
+			return PurchaseOrderPartial
+		}
+	}
+	return PurchaseOrderReceived
+}
+
+type PurchaseReceipt struct {
+	ID              uuid.UUID             `gorm:"type:uuid;primaryKey"`
+	PurchaseOrderID uuid.UUID             `gorm:"type:uuid"`
+	LocationID      uuid.UUID             `gorm:"type:uuid"`
+	ReceivedBy      *uuid.UUID            `gorm:"type:uuid"`
+	Comment         string                `gorm:"type:text"`
+	CreatedAt       time.Time             `gorm:"type:timestamptz"`
+	Lines           []PurchaseReceiptLine `gorm:"foreignKey:ReceiptID"`
+}
+
+type PurchaseReceiptLine struct {
+	ReceiptID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LineID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Received  int       `gorm:"type:int"`
+	Damaged   int       `gorm:"type:int"`
+}
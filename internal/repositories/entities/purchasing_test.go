@@ -0,0 +1,48 @@
+package entities
+
+import "testing"
+
+func TestPurchaseOrderLineOutstanding(t *testing.T) {
+	cases := []struct {
+		name                        string
+		expected, received, damaged int
+		want                        int
+	}{
+		{"nothing received", 10, 0, 0, 10},
+		{"partial", 10, 6, 0, 4},
+		{"damaged units count as delivered", 10, 6, 1, 3},
+		{"only damaged", 4, 0, 4, 0},
+		{"complete", 10, 10, 0, 0},
+		{"over-received", 10, 11, 1, 0},
+	}
+	for _, tc := range cases {
+		line := PurchaseOrderLine{ExpectedQuantity: tc.expected, ReceivedQuantity: tc.received, DamagedQuantity: tc.damaged}
+		if got := line.Outstanding(); got != tc.want {
+			t.Errorf("%s: Outstanding = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestStatusAfterReceipt(t *testing.T) {
+	cases := []struct {
+		name  string
+		lines []PurchaseOrderLine
+		want  string
+	}{
+		{"one line short", []PurchaseOrderLine{
+			{ExpectedQuantity: 10, ReceivedQuantity: 10},
+			{ExpectedQuantity: 4, ReceivedQuantity: 1, DamagedQuantity: 1},
+		}, PurchaseOrderPartial},
+		{"all lines delivered", []PurchaseOrderLine{
+			{ExpectedQuantity: 10, ReceivedQuantity: 9, DamagedQuantity: 1},
+			{ExpectedQuantity: 4, DamagedQuantity: 4},
+		}, PurchaseOrderReceived},
+		{"over-received", []PurchaseOrderLine{{ExpectedQuantity: 10, ReceivedQuantity: 12}}, PurchaseOrderReceived},
+		{"nothing received", []PurchaseOrderLine{{ExpectedQuantity: 1}}, PurchaseOrderPartial},
+	}
+	for _, tc := range cases {
+		if got := StatusAfterReceipt(tc.lines); got != tc.want {
+			t.Errorf("%s: status = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
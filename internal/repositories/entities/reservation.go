@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReservationActive    = "active"
+	ReservationConfirmed = "confirmed"
+	ReservationCancelled = "cancelled"
+	ReservationExpired   = "expired"
+)
+
+type Reservation struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	BookID    uuid.UUID  `gorm:"type:uuid"`
+	Quantity  int        `gorm:"type:int"`
+	Status    string     `gorm:"type:text"`
+	Reference string     `gorm:"type:text"`
+	ExpiresAt time.Time  `gorm:"type:timestamptz"`
+	CreatedBy *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt time.Time  `gorm:"type:timestamptz"`
+	UpdatedAt time.Time  `gorm:"type:timestamptz"`
+}
@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MovementReasonReceipt    = "receipt"    // приёмка
+	MovementReasonSale       = "sale"       // продажа/отгрузка
+	MovementReasonAdjustment = "adjustment" // корректировка по инвентаризации
+	MovementReasonDamage     = "damage"     // списание брака
+	MovementReasonReturn     = "return"     // возврат от покупателя
+	MovementReasonTransfer   = "transfer"   // перемещение между ячейками
+)
+
+type StockMovement struct {
+	ID              int64      `gorm:"primaryKey"`
+	BookID          uuid.UUID  `gorm:"type:uuid"`
+	EditionID       uuid.UUID  `gorm:"type:uuid"`
+	LocationID      uuid.UUID  `gorm:"type:uuid"`
+	Delta           int        `gorm:"type:int"`
+	LocationBalance int        `gorm:"type:int"`
+	Balance         int        `gorm:"type:int"`
+	Reason          string     `gorm:"type:text"`
+	ActorID         *uuid.UUID `gorm:"type:uuid"`
+	CorrelationID   string     `gorm:"type:text"`
+	Comment         string     `gorm:"type:text"`
+	CreatedAt       time.Time  `gorm:"type:timestamptz"`
+}
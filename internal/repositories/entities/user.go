@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type User struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Login            string     `gorm:"type:text;uniqueIndex"`
+	PasswordHash     string     `gorm:"type:text"`
+	Role             string     `gorm:"type:text"`
+	Disabled         bool       `gorm:"type:boolean;not null;default:false"`
+	LastLoginAt      *time.Time `gorm:"type:timestamptz"`
+	TokensValidAfter *time.Time `gorm:"type:timestamptz"` // токены, выпущенные раньше, недействительны; nil - ограничения нет
+	CreatedAt        time.Time  `gorm:"type:timestamptz"`
+	UpdatedAt        time.Time  `gorm:"type:timestamptz"`
+}
+
+// RevokedToken отозванный refresh токен; запись нужна только до истечения срока токена
+type RevokedToken struct {
+	TokenID   string    `gorm:"type:text;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid"`
+	ExpiresAt time.Time `gorm:"type:timestamptz"`
+	RevokedAt time.Time `gorm:"type:timestamptz"`
+}
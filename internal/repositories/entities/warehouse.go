@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+)
+
+type Warehouse struct {
+	ID      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Code    string    `gorm:"type:text;uniqueIndex"`
+	Name    string    `gorm:"type:text"`
+	Address string    `gorm:"type:text"`
+}
+
+type Location struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	WarehouseID uuid.UUID `gorm:"type:uuid"`
+	Aisle       string    `gorm:"type:text"`
+	Shelf       string    `gorm:"type:text"`
+	Bin         string    `gorm:"type:text"`
+	Warehouse   Warehouse
+}
+
+type StockLevel struct {
+	BookID     uuid.UUID `gorm:"type:uuid"`
+	EditionID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LocationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Quantity   int       `gorm:"type:int"`
+	Location   Location
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/idempotency"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type idempotencyRepository struct {
+	database *gorm.DB
+}
+
+// NewIdempotencyRepository хранит ключи идемпотентности в таблице idempotency_keys
+func NewIdempotencyRepository(database *gorm.DB) idempotency.Store {
+	return &idempotencyRepository{database: database}
+}
+
+func (r *idempotencyRepository) Begin(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (*idempotency.Record, bool, error) {
+	var record *idempotency.Record
+	created := false
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&entities.IdempotencyKey{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.IdempotencyKey{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			created = true
+			return nil
+		}
+		var existing entities.IdempotencyKey
+		if err := tx.First(&existing, "scope = ? and key = ?", scope, key).Error; err != nil {
+			return err
+		}
+		record = &idempotency.Record{
+			RequestHash: existing.RequestHash,
+			Completed:   existing.Completed,
+			StatusCode:  existing.StatusCode,
+			ContentType: existing.ContentType,
+			Body:        existing.ResponseBody,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return record, created, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	return r.database.WithContext(ctx).Model(&entities.IdempotencyKey{}).
+		Where("scope = ? and key = ?", scope, key).
+		Updates(map[string]any{
+			"completed":     true,
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+		}).Error
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	return r.database.WithContext(ctx).Where("scope = ? and key = ?", scope, key).Delete(&entities.IdempotencyKey{}).Error
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"gin_main/internal/repositories/entities"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImportRepositoryInterface interface {
+	ImportBooks(ctx context.Context, books []entities.Book) (int, error) // в одной транзакции находит или создаёт авторов и создаёт книги с неописанным изданием, возвращает число новых авторов
+	ExistingISBNs(ctx context.Context, isbns []string) ([]string, error) // вернёт ISBN из списка, уже занятые книгами вне корзины
+}
+
+type importRepository struct {
+	database *gorm.DB
+}
+
+func NewImportRepository(database *gorm.DB) ImportRepositoryInterface {
+	return &importRepository{database: database}
+}
+
+func (r *importRepository) ImportBooks(ctx context.Context, books []entities.Book) (int, error) {
+	authorsCreated := 0
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// параллельный импорт того же каталога не должен завести автора дважды
+		if err := tx.Exec("select pg_advisory_xact_lock(hashtext('authors-import'))").Error; err != nil {
+			return err
+		}
+		authorIDs := make(map[string]uuid.UUID)
+		var contributors []entities.BookContributor
+		editions := make([]entities.Edition, 0, len(books))
+		for i := range books {
+			books[i].ID = uuid.New()
+			books[i].Title = strings.ToTitle(books[i].Title)
+			for position, contributor := range books[i].Contributors {
+				key := authorKey(contributor.Author)
+				authorID, ok := authorIDs[key]
+				if !ok {
+					author, created, err := findOrCreateAuthor(tx, contributor.Author)
+					if err != nil {
+						return err
+					}
+					if created {
+						authorsCreated++
+					}
+					authorID = author.ID
+					authorIDs[key] = authorID
+				}
+				contributors = append(contributors, entities.BookContributor{
+					BookID:   books[i].ID,
+					AuthorID: authorID,
+					Role:     contributor.Role,
+					Position: position,
+				})
+			}
+			editions = append(editions, entities.Edition{ID: uuid.New(), BookID: books[i].ID, Format: entities.EditionUnspecified})
+		}
+		if err := tx.Omit(clause.Associations).CreateInBatches(&books, 100).Error; err != nil {
+			return err
+		}
+		if len(contributors) > 0 {
+			if err := tx.Omit("Author").CreateInBatches(&contributors, 100).Error; err != nil {
+				return err
+			}
+		}
+		return tx.CreateInBatches(&editions, 100).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return authorsCreated, nil
+}
+
+func (r *importRepository) ExistingISBNs(ctx context.Context, isbns []string) ([]string, error) {
+	existing := []string{}
+	if len(isbns) == 0 {
+		return existing, nil
+	}
+	if err := r.database.WithContext(ctx).Model(&entities.Book{}).Where("isbn IN ?", isbns).Pluck("isbn", &existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// authorKey - ФИО без учёта регистра плюс дата рождения: так импорт отличает однофамильцев
+func authorKey(author entities.Author) string {
+	return strings.ToLower(author.Surname+"|"+author.FirstName+"|"+author.SecondName) + "|" + author.DateOfBirth.Format(time.DateOnly)
+}
+
+func findOrCreateAuthor(tx *gorm.DB, author entities.Author) (entities.Author, bool, error) {
+	var existing entities.Author
+	// SHARE-блокировка, как у записи книги: найденного автора нельзя удалить до конца импорта
+	result := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("lower(surname) = lower(?) and lower(first_name) = lower(?) and lower(second_name) = lower(?) and coalesce(date_of_birth, '0001-01-01') = ?",
+		author.Surname, author.FirstName, author.SecondName, author.DateOfBirth).
+		Limit(1).
+		Find(&existing)
+	if result.Error != nil {
+		return entities.Author{}, false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return existing, false, nil
+	}
+	author.ID = uuid.New()
+	if err := tx.Create(&author).Error; err != nil {
+		return entities.Author{}, false, err
+	}
+	return author, true, nil
+}
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/repositories/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrOrderStatusChanged = errors.New("order status was changed concurrently")
+
+type OrderRepositoryInterface interface {
+	Create(ctx context.Context, order entities.Order) (entities.Order, error) // создаёт заказ вместе со строками
+	FindById(ctx context.Context, id uuid.UUID) (entities.Order, error)       // найдёт заказ со строками
+	GetAll(ctx context.Context, status string) ([]entities.Order, error)      // возвращает заказы, при непустом status только в этом статусе
+	// ChangeStatus переводит заказ из from в to, выполняя побочные эффекты перехода в одной транзакции:
+	// picking резервирует остаток по строкам, shipped списывает его через ChangeQuantity, cancelled снимает резервы
+	ChangeStatus(ctx context.Context, id uuid.UUID, from, to string, reservationExpiresAt time.Time, info MovementInfo) (entities.Order, error)
+}
+
+type orderRepository struct {
+	database *gorm.DB
+}
+
+func NewOrderRepository(database *gorm.DB) OrderRepositoryInterface {
+	return &orderRepository{database: database}
+}
+
+func (r *orderRepository) Create(ctx context.Context, order entities.Order) (entities.Order, error) {
+	if order.ID == uuid.Nil {
+		order.ID = uuid.New()
+	}
+	order.Status = entities.OrderDraft
+	for i := range order.Lines {
+		order.Lines[i].ID = uuid.New()
+		order.Lines[i].OrderID = order.ID
+	}
+	if result := r.database.WithContext(ctx).Create(&order); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return entities.Order{}, gorm.ErrRecordNotFound
+		}
+		return entities.Order{}, result.Error
+	}
+	return order, nil
+}
+
+func (r *orderRepository) FindById(ctx context.Context, id uuid.UUID) (entities.Order, error) {
+	var order entities.Order
+	if result := r.database.WithContext(ctx).Preload("Lines").First(&order, "id = ?", id); result.Error != nil {
+		return entities.Order{}, result.Error
+	}
+	return order, nil
+}
+
+func (r *orderRepository) GetAll(ctx context.Context, status string) ([]entities.Order, error) {
+	var orders []entities.Order
+	query := r.database.WithContext(ctx).Preload("Lines").Order("created_at desc")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if results := query.Find(&orders); results.Error != nil {
+		return nil, results.Error
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) ChangeStatus(ctx context.Context, id uuid.UUID, from, to string, reservationExpiresAt time.Time, info MovementInfo) (entities.Order, error) {
+	var order entities.Order
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Lines").First(&order, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if order.Status != from {
+			return ErrOrderStatusChanged
+		}
+		var err error
+		switch to {
+		case entities.OrderPicking:
+			err = reserveOrderLines(tx, &order, reservationExpiresAt)
+		case entities.OrderShipped:
+			err = shipOrderLines(tx, &order, info)
+		case entities.OrderCancelled:
+			err = releaseOrderLines(tx, &order)
+		}
+		if err != nil {
+			return err
+		}
+		order.Status = to
+		return tx.Model(&order).Update("status", to).Error
+	})
+	if err != nil {
+		return entities.Order{}, err
+	}
+	return order, nil
+}
+
+func reserveOrderLines(tx *gorm.DB, order *entities.Order, expiresAt time.Time) error {
+	reservationRepo := NewReservationRepository(tx)
+	for i, line := range order.Lines {
+		reservation, err := reservationRepo.Create(tx.Statement.Context, entities.Reservation{
+			BookID:    line.BookID,
+			Quantity:  line.Quantity,
+			Reference: fmt.Sprintf("order %s", order.ID),
+			ExpiresAt: expiresAt,
+			CreatedBy: order.CreatedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("book %s: %w", line.BookID, err)
+		}
+		order.Lines[i].ReservationID = &reservation.ID
+		if err := tx.Model(&order.Lines[i]).Update("reservation_id", reservation.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func shipOrderLines(tx *gorm.DB, order *entities.Order, info MovementInfo) error {
+	bookRepo := NewBookRepository(tx)
+	info.Reason = entities.MovementReasonSale
+	info.Comment = fmt.Sprintf("order %s", order.ID)
+	for _, line := range order.Lines {
+		// резерв строки снимается перед списанием, иначе ChangeQuantity посчитает остаток занятым;
+		// истёкший резерв не мешает отгрузке, если остатка всё ещё хватает
+		if err := consumeReservation(tx, line.ReservationID); err != nil {
+			return err
+		}
+		var levels []entities.StockLevel
+		err := tx.Where("book_id = ? and quantity > 0", line.BookID).
+			Order("quantity desc, location_id, edition_id").
+			Find(&levels).Error
+		if err != nil {
+			return err
+		}
+		remaining := line.Quantity
+		for _, level := range levels {
+			if remaining == 0 {
+				break
+			}
+			take := min(level.Quantity, remaining)
+			if _, _, err := bookRepo.ChangeQuantity(tx.Statement.Context, line.BookID, level.EditionID, level.LocationID, -take, info); err != nil {
+				return fmt.Errorf("book %s: %w", line.BookID, err)
+			}
+			remaining -= take
+		}
+		if remaining > 0 {
+			return fmt.Errorf("book %s: %w", line.BookID, ErrInsufficientStock)
+		}
+	}
+	return nil
+}
+
+func releaseOrderLines(tx *gorm.DB, order *entities.Order) error {
+	for _, line := range order.Lines {
+		if line.ReservationID == nil {
+			continue
+		}
+		reservation, err := lockActiveReservation(tx, *line.ReservationID)
+		if errors.Is(err, ErrReservationNotActive) || errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := setReservationStatus(tx, &reservation, entities.ReservationCancelled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func consumeReservation(tx *gorm.DB, reservationID *uuid.UUID) error {
+	if reservationID == nil {
+		return nil
+	}
+	reservation, err := lockActiveReservation(tx, *reservationID)
+	if errors.Is(err, ErrReservationNotActive) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return setReservationStatus(tx, &reservation, entities.ReservationConfirmed)
+}
@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/repositories/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSupplierExists         = errors.New("supplier with this name already exists")
+	ErrPurchaseOrderNotOpen   = errors.New("purchase order is not open")
+	ErrBookNotOnPurchaseOrder = errors.New("book is not on purchase order")
+	ErrUnknownLocation        = errors.New("location does not exist")
+	ErrUnknownEdition         = errors.New("edition does not belong to book")
+)
+
+// ReceiptItem - принятое по одной строке заказа количество: годные единицы идут на склад, повреждённые только учитываются
+type ReceiptItem struct {
+	BookID    uuid.UUID
+	EditionID uuid.UUID // издание, на которое приходуется остаток; uuid.Nil - единственное издание книги
+	Received  int
+	Damaged   int
+}
+
+type PurchaseOrderRepositoryInterface interface {
+	CreateSupplier(ctx context.Context, supplier entities.Supplier) (entities.Supplier, error)                                                               // создаёт поставщика с уникальным названием
+	GetAllSuppliers(ctx context.Context) ([]entities.Supplier, error)                                                                                        // возвращает всех поставщиков
+	FindSupplierById(ctx context.Context, id uuid.UUID) (entities.Supplier, error)                                                                           // найдёт поставщика по id
+	Create(ctx context.Context, order entities.PurchaseOrder) (entities.PurchaseOrder, error)                                                                // создаёт заказ поставщику со строками
+	FindById(ctx context.Context, id uuid.UUID) (entities.PurchaseOrder, error)                                                                              // найдёт заказ с поставщиком и строками
+	GetAll(ctx context.Context, status string, supplierID uuid.UUID) ([]entities.PurchaseOrder, error)                                                       // возвращает заказы с необязательными фильтрами
+	GetPending(ctx context.Context) ([]entities.PurchaseOrder, error)                                                                                        // незакрытые заказы, по ожидаемой дате поставки
+	GetReceipts(ctx context.Context, id uuid.UUID) ([]entities.PurchaseReceipt, error)                                                                       // история приёмок по заказу
+	Close(ctx context.Context, id uuid.UUID) (entities.PurchaseOrder, error)                                                                                 // закрывает заказ без ожидания оставшихся единиц
+	Receive(ctx context.Context, id, locationID uuid.UUID, items []ReceiptItem, info MovementInfo) (entities.PurchaseOrder, entities.PurchaseReceipt, error) // принимает поставку и увеличивает остаток через журнал
+}
+
+type purchaseOrderRepository struct {
+	database *gorm.DB
+}
+
+func NewPurchaseOrderRepository(database *gorm.DB) PurchaseOrderRepositoryInterface {
+	return &purchaseOrderRepository{database: database}
+}
+
+func (r *purchaseOrderRepository) CreateSupplier(ctx context.Context, supplier entities.Supplier) (entities.Supplier, error) {
+	if supplier.ID == uuid.Nil {
+		supplier.ID = uuid.New()
+	}
+	if result := r.database.WithContext(ctx).Create(&supplier); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return entities.Supplier{}, ErrSupplierExists
+		}
+		return entities.Supplier{}, result.Error
+	}
+	return supplier, nil
+}
+
+func (r *purchaseOrderRepository) GetAllSuppliers(ctx context.Context) ([]entities.Supplier, error) {
+	var suppliers []entities.Supplier
+	if results := r.database.WithContext(ctx).Order("name").Find(&suppliers); results.Error != nil {
+		return nil, results.Error
+	}
+	return suppliers, nil
+}
+
+func (r *purchaseOrderRepository) FindSupplierById(ctx context.Context, id uuid.UUID) (entities.Supplier, error) {
+	var supplier entities.Supplier
+	if result := r.database.WithContext(ctx).First(&supplier, "id = ?", id); result.Error != nil {
+		return entities.Supplier{}, result.Error
+	}
+	return supplier, nil
+}
+
+func (r *purchaseOrderRepository) Create(ctx context.Context, order entities.PurchaseOrder) (entities.PurchaseOrder, error) {
+	if order.ID == uuid.Nil {
+		order.ID = uuid.New()
+	}
+	order.Status = entities.PurchaseOrderOpen
+	for i := range order.Lines {
+		order.Lines[i].ID = uuid.New()
+		order.Lines[i].PurchaseOrderID = order.ID
+	}
+	if result := r.database.WithContext(ctx).Omit("Supplier").Create(&order); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return entities.PurchaseOrder{}, gorm.ErrRecordNotFound
+		}
+		return entities.PurchaseOrder{}, result.Error
+	}
+	return r.FindById(ctx, order.ID)
+}
+
+func (r *purchaseOrderRepository) FindById(ctx context.Context, id uuid.UUID) (entities.PurchaseOrder, error) {
+	var order entities.PurchaseOrder
+	if result := r.database.WithContext(ctx).Preload("Supplier").Preload("Lines").First(&order, "id = ?", id); result.Error != nil {
+		return entities.PurchaseOrder{}, result.Error
+	}
+	return order, nil
+}
+
+func (r *purchaseOrderRepository) GetAll(ctx context.Context, status string, supplierID uuid.UUID) ([]entities.PurchaseOrder, error) {
+	var orders []entities.PurchaseOrder
+	query := r.database.WithContext(ctx).Preload("Supplier").Preload("Lines").Order("created_at desc")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if supplierID != uuid.Nil {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+	if results := query.Find(&orders); results.Error != nil {
+		return nil, results.Error
+	}
+	return orders, nil
+}
+
+func (r *purchaseOrderRepository) GetPending(ctx context.Context) ([]entities.PurchaseOrder, error) {
+	var orders []entities.PurchaseOrder
+	results := r.database.WithContext(ctx).Preload("Supplier").Preload("Lines").
+		Where("status in ?", []string{entities.PurchaseOrderOpen, entities.PurchaseOrderPartial}).
+		Order("expected_at, created_at").
+		Find(&orders)
+	if results.Error != nil {
+		return nil, results.Error
+	}
+	return orders, nil
+}
+
+func (r *purchaseOrderRepository) GetReceipts(ctx context.Context, id uuid.UUID) ([]entities.PurchaseReceipt, error) {
+	var receipts []entities.PurchaseReceipt
+	if results := r.database.WithContext(ctx).Preload("Lines").Where("purchase_order_id = ?", id).Order("created_at").Find(&receipts); results.Error != nil {
+		return nil, results.Error
+	}
+	return receipts, nil
+}
+
+func (r *purchaseOrderRepository) Close(ctx context.Context, id uuid.UUID) (entities.PurchaseOrder, error) {
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPendingPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&order).Updates(map[string]any{"status": entities.PurchaseOrderClosed, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return entities.PurchaseOrder{}, err
+	}
+	return r.FindById(ctx, id)
+}
+
+func (r *purchaseOrderRepository) Receive(ctx context.Context, id, locationID uuid.UUID, items []ReceiptItem, info MovementInfo) (entities.PurchaseOrder, entities.PurchaseReceipt, error) {
+	receipt := entities.PurchaseReceipt{
+		ID:              uuid.New(),
+		PurchaseOrderID: id,
+		LocationID:      locationID,
+		Comment:         info.Comment,
+	}
+	if info.ActorID != uuid.Nil {
+		receipt.ReceivedBy = &info.ActorID
+	}
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPendingPurchaseOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&entities.Location{}, "id = ?", locationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUnknownLocation
+			}
+			return err
+		}
+		var lines []entities.PurchaseOrderLine
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("purchase_order_id = ?", id).Find(&lines).Error; err != nil {
+			return err
+		}
+		linesByBook := make(map[uuid.UUID]*entities.PurchaseOrderLine, len(lines))
+		for i := range lines {
+			linesByBook[lines[i].BookID] = &lines[i]
+		}
+		if err := tx.Omit("Lines").Create(&receipt).Error; err != nil {
+			return err
+		}
+
+		bookRepo := NewBookRepository(tx)
+		movement := info
+		movement.Reason = entities.MovementReasonReceipt
+		movement.Comment = fmt.Sprintf("purchase order %s", id)
+		for _, item := range items {
+			line, ok := linesByBook[item.BookID]
+			if !ok {
+				return fmt.Errorf("book %s: %w", item.BookID, ErrBookNotOnPurchaseOrder)
+			}
+			// повреждённые единицы закрывают ожидаемое количество, но на склад не поступают
+			if item.Received > 0 {
+				if _, _, err := bookRepo.ChangeQuantity(tx.Statement.Context, item.BookID, item.EditionID, locationID, item.Received, movement); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						err = ErrUnknownEdition
+					}
+					return fmt.Errorf("book %s: %w", item.BookID, err)
+				}
+			}
+			line.ReceivedQuantity += item.Received
+			line.DamagedQuantity += item.Damaged
+			err := tx.Model(line).Updates(map[string]any{
+				"received_quantity": line.ReceivedQuantity,
+				"damaged_quantity":  line.DamagedQuantity,
+			}).Error
+			if err != nil {
+				return err
+			}
+			receiptLine := entities.PurchaseReceiptLine{ReceiptID: receipt.ID, LineID: line.ID, Received: item.Received, Damaged: item.Damaged}
+			if err := tx.Create(&receiptLine).Error; err != nil {
+				return err
+			}
+			receipt.Lines = append(receipt.Lines, receiptLine)
+		}
+
+		status := entities.StatusAfterReceipt(lines)
+		return tx.Model(&order).Updates(map[string]any{"status": status, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return entities.PurchaseOrder{}, entities.PurchaseReceipt{}, err
+	}
+	order, err := r.FindById(ctx, id)
+	return order, receipt, err
+}
+
+func lockPendingPurchaseOrder(tx *gorm.DB, id uuid.UUID) (entities.PurchaseOrder, error) {
+	var order entities.PurchaseOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", id).Error; err != nil {
+		return entities.PurchaseOrder{}, err
+	}
+	if order.Status != entities.PurchaseOrderOpen && order.Status != entities.PurchaseOrderPartial {
+		return entities.PurchaseOrder{}, ErrPurchaseOrderNotOpen
+	}
+	return order, nil
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/repositories/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrStockReserved        = errors.New("stock is held by active reservations")
+	ErrReservationNotActive = errors.New("reservation is not active")
+)
+
+type ReservationRepositoryInterface interface {
+	Create(ctx context.Context, reservation entities.Reservation) (entities.Reservation, error) // резервирует, если доступно не меньше запрошенного
+	FindById(ctx context.Context, id uuid.UUID) (entities.Reservation, error)                   // найдёт резерв по id
+	Confirm(ctx context.Context, id uuid.UUID, info MovementInfo) (entities.Reservation, error) // превращает резерв в списание остатка
+	Cancel(ctx context.Context, id uuid.UUID) (entities.Reservation, error)                     // отменяет активный резерв
+	ExpireOverdue(ctx context.Context) (int64, error)                                           // помечает просроченные резервы, возвращает их количество
+}
+
+type reservationRepository struct {
+	database *gorm.DB
+}
+
+func NewReservationRepository(database *gorm.DB) ReservationRepositoryInterface {
+	return &reservationRepository{database: database}
+}
+
+func (r *reservationRepository) Create(ctx context.Context, reservation entities.Reservation) (entities.Reservation, error) {
+	if reservation.ID == uuid.Nil {
+		reservation.ID = uuid.New()
+	}
+	reservation.Status = entities.ReservationActive
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		onHand, reserved, err := lockBookStock(tx, reservation.BookID)
+		if err != nil {
+			return err
+		}
+		if onHand-reserved < reservation.Quantity {
+			return ErrInsufficientStock
+		}
+		return tx.Create(&reservation).Error
+	})
+	if err != nil {
+		return entities.Reservation{}, err
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepository) FindById(ctx context.Context, id uuid.UUID) (entities.Reservation, error) {
+	var reservation entities.Reservation
+	if result := r.database.WithContext(ctx).First(&reservation, "id = ?", id); result.Error != nil {
+		return entities.Reservation{}, result.Error
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepository) Confirm(ctx context.Context, id uuid.UUID, info MovementInfo) (entities.Reservation, error) {
+	var reservation entities.Reservation
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if reservation, err = lockActiveReservation(tx, id); err != nil {
+			return err
+		}
+		if _, _, err = lockBookStock(tx, reservation.BookID); err != nil {
+			return err
+		}
+		// списываем из ячеек с наибольшим остатком, чтобы затронуть как можно меньше мест
+		var levels []entities.StockLevel
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("book_id = ? and quantity > 0", reservation.BookID).
+			Order("quantity desc, location_id, edition_id").
+			Find(&levels).Error
+		if err != nil {
+			return err
+		}
+		info.Reason = entities.MovementReasonSale
+		if info.Comment == "" {
+			info.Comment = fmt.Sprintf("reservation %s", reservation.ID)
+		}
+		remaining := reservation.Quantity
+		for _, level := range levels {
+			if remaining == 0 {
+				break
+			}
+			take := min(level.Quantity, remaining)
+			if _, err := changeStockLevel(tx, reservation.BookID, level.EditionID, level.LocationID, -take, info); err != nil {
+				return err
+			}
+			remaining -= take
+		}
+		if remaining > 0 {
+			return ErrInsufficientStock
+		}
+		return setReservationStatus(tx, &reservation, entities.ReservationConfirmed)
+	})
+	if err != nil {
+		return entities.Reservation{}, err
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepository) Cancel(ctx context.Context, id uuid.UUID) (entities.Reservation, error) {
+	var reservation entities.Reservation
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if reservation, err = lockActiveReservation(tx, id); err != nil {
+			return err
+		}
+		return setReservationStatus(tx, &reservation, entities.ReservationCancelled)
+	})
+	if err != nil {
+		return entities.Reservation{}, err
+	}
+	return reservation, nil
+}
+
+func (r *reservationRepository) ExpireOverdue(ctx context.Context) (int64, error) {
+	result := r.database.WithContext(ctx).Model(&entities.Reservation{}).
+		Where("status = ? and expires_at <= now()", entities.ReservationActive).
+		Updates(map[string]any{"status": entities.ReservationExpired, "updated_at": gorm.Expr("now()")})
+	return result.RowsAffected, result.Error
+}
+
+// lockBookStock блокирует строку книги, сериализуя резервирование и списания по ней, и возвращает остаток и объём резервов
+func lockBookStock(tx *gorm.DB, bookID uuid.UUID) (int, int, error) {
+	var locked entities.Book
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, "id = ?", bookID).Error; err != nil {
+		return 0, 0, err
+	}
+	var stock struct {
+		OnHand   int
+		Reserved int
+	}
+	err := tx.Raw("select st.on_hand, st.reserved from books cross join lateral ("+stockSubquery+") st where books.id = ?", bookID).
+		Scan(&stock).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return stock.OnHand, stock.Reserved, nil
+}
+
+func lockActiveReservation(tx *gorm.DB, id uuid.UUID) (entities.Reservation, error) {
+	var reservation entities.Reservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, "id = ?", id).Error; err != nil {
+		return entities.Reservation{}, err
+	}
+	if reservation.Status != entities.ReservationActive || !reservation.ExpiresAt.After(time.Now()) {
+		return entities.Reservation{}, ErrReservationNotActive
+	}
+	return reservation, nil
+}
+
+func setReservationStatus(tx *gorm.DB, reservation *entities.Reservation, status string) error {
+	reservation.Status = status
+	return tx.Model(reservation).Update("status", status).Error
+}
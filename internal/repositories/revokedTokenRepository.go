@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"gin_main/internal/repositories/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedTokenRepositoryInterface interface {
+	Revoke(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) (bool, error) // отзывает refresh токен, вернёт false, если он уже был отозван
+}
+
+type revokedTokenRepository struct {
+	database *gorm.DB
+}
+
+// NewRevokedTokenRepository хранит отозванные refresh токены в таблице revoked_tokens
+func NewRevokedTokenRepository(database *gorm.DB) RevokedTokenRepositoryInterface {
+	return &revokedTokenRepository{database: database}
+}
+
+func (r *revokedTokenRepository) Revoke(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) (bool, error) {
+	revoked := false
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// истёкший токен не пройдёт проверку подписи, поэтому его запись больше не нужна
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&entities.RevokedToken{}).Error; err != nil {
+			return err
+		}
+		// вставка и проверка одним запросом: два инстанса не смогут обменять один токен дважды
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.RevokedToken{
+			TokenID:   tokenID,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+			RevokedAt: time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		revoked = result.RowsAffected == 1
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/textsearch"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+
+type BookSearchResult struct {
+	Book            entities.Book
+	Rank            float64
+	TitleHighlight  string
+	AuthorHighlight string
+}
+
+type AuthorSearchResult struct {
+	entities.Author
+	Rank      float64
+	Highlight string
+}
+
+type SearchRepositoryInterface interface {
+	SearchBooks(ctx context.Context, query textsearch.Query, limit, offset int) ([]BookSearchResult, int64, error)     // полнотекстовый поиск книг по названию и автору с учётом опечаток
+	SearchAuthors(ctx context.Context, query textsearch.Query, limit, offset int) ([]AuthorSearchResult, int64, error) // поиск авторов по ФИО в любом порядке и по инициалам
+}
+
+type searchRepository struct {
+	database *gorm.DB
+}
+
+func NewSearchRepository(database *gorm.DB) SearchRepositoryInterface {
+	return &searchRepository{database: database}
+}
+
+func (r *searchRepository) SearchBooks(ctx context.Context, query textsearch.Query, limit, offset int) ([]BookSearchResult, int64, error) {
+	match := newSearchMatch(query, "b.search_vector", "russian", "lower(b.title)", "lower(a.surname)")
+	// книга попадает в выборку по каждому совпавшему участнику, поэтому считаются и ранжируются уникальные книги
+	base := r.database.WithContext(ctx).Table("books b").
+		Joins("join book_contributors bc on bc.book_id = b.id").
+		Joins("join authors a on a.id = bc.author_id").
+		Where("b.deleted_at is null").
+		Where(match.where, match.whereArgs...).
+		Scopes(initialsFilter(query)).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := base.Distinct("b.id").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []struct {
+		ID              uuid.UUID
+		Rank            float64
+		TitleHighlight  string
+		AuthorHighlight string
+	}
+	columns, args := match.selectColumns("b.title", "a.surname || ' ' || a.first_name || ' ' || a.second_name")
+	best := base.Select("distinct on (b.id) b.id, b.title as sort_title, "+columns, args...).Order("b.id, rank desc")
+	err := r.database.WithContext(ctx).Table("(?) hits", best).
+		Select("id, rank, title_highlight, author_highlight").
+		Order("rank desc, sort_title").
+		Limit(limit).
+		Offset(offset).
+		Scan(&hits).Error
+	if err != nil || len(hits) == 0 {
+		return nil, total, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	var books []entities.Book
+	if err := r.database.WithContext(ctx).Scopes(withStock, withDetails).Find(&books, "books.id in ?", ids).Error; err != nil {
+		return nil, 0, err
+	}
+	booksById := make(map[uuid.UUID]entities.Book, len(books))
+	for _, book := range books {
+		booksById[book.ID] = book
+	}
+	results := make([]BookSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		book, ok := booksById[hit.ID]
+		if !ok { // книгу удалили между запросами
+			continue
+		}
+		results = append(results, BookSearchResult{
+			Book:            book,
+			Rank:            hit.Rank,
+			TitleHighlight:  hit.TitleHighlight,
+			AuthorHighlight: hit.AuthorHighlight,
+		})
+	}
+	return results, total, nil
+}
+
+func (r *searchRepository) SearchAuthors(ctx context.Context, query textsearch.Query, limit, offset int) ([]AuthorSearchResult, int64, error) {
+	match := newSearchMatch(query, "a.search_vector", "simple", "lower(a.surname)")
+	base := r.database.WithContext(ctx).Table("authors a").
+		Where("a.deleted_at is null").
+		Where(match.where, match.whereArgs...).
+		Scopes(initialsFilter(query)).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var results []AuthorSearchResult
+	columns, args := match.selectColumns("a.surname || ' ' || a.first_name || ' ' || a.second_name")
+	err := base.Select("a.id, a.date_of_birth, a.first_name, a.second_name, a.surname, "+columns, args...).
+		Order("rank desc, a.surname").
+		Limit(limit).
+		Offset(offset).
+		Scan(&results).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+
+// searchMatch собирает условие совпадения и выражение ранга: tsvector по словам запроса
+// плюс сходство по триграммам с каждым вариантом транслитерации, чтобы находить опечатки
+type searchMatch struct {
+	query      textsearch.Query
+	config     string
+	where      string
+	whereArgs  []any
+	rank       string
+	rankArgs   []any
+	similarity []string
+}
+
+func newSearchMatch(query textsearch.Query, vector, config string, trigramColumns ...string) searchMatch {
+	m := searchMatch{query: query, config: config, similarity: trigramColumns}
+	var conditions []string
+	rank := []string{}
+	if ts := query.TSQuery(); ts != "" {
+		tsquery, tsArgs := "to_tsquery('simple', ?)", []any{ts}
+		if config != "simple" {
+			tsquery, tsArgs = "(to_tsquery('"+config+"', ?) || to_tsquery('simple', ?))", []any{ts, ts}
+		}
+		conditions = append(conditions, vector+" @@ "+tsquery)
+		m.whereArgs = append(m.whereArgs, tsArgs...)
+		rank = append(rank, "ts_rank("+vector+", "+tsquery+")")
+		m.rankArgs = append(m.rankArgs, tsArgs...)
+	}
+	if text := query.Text(); text != "" {
+		var similarities []string
+		for _, variant := range textsearch.Variants(text) {
+			for _, column := range trigramColumns {
+				conditions = append(conditions, "? <% "+column)
+				m.whereArgs = append(m.whereArgs, variant)
+				similarities = append(similarities, "word_similarity(?, "+column+")")
+				m.rankArgs = append(m.rankArgs, variant)
+			}
+		}
+		rank = append(rank, "greatest("+strings.Join(similarities, ", ")+")")
+	}
+	if len(conditions) == 0 {
+		// запрос только из инициалов: ограничивает лишь initialsFilter
+		m.where = "true"
+		m.rank = "0"
+		return m
+	}
+	m.where = "(" + strings.Join(conditions, " or ") + ")"
+	m.rank = strings.Join(rank, " + ")
+	return m
+}
+
+// selectColumns возвращает ранг и подсветку для каждого из переданных текстовых выражений
+func (m searchMatch) selectColumns(highlighted ...string) (string, []any) {
+	columns := []string{m.rank + " as rank"}
+	args := append([]any{}, m.rankArgs...)
+	names := []string{"title_highlight", "author_highlight"}
+	if len(highlighted) == 1 {
+		names = []string{"highlight"}
+	}
+	anyQuery := m.query.AnyTSQuery()
+	for i, expression := range highlighted {
+		if anyQuery == "" {
+			columns = append(columns, expression+" as "+names[i])
+			continue
+		}
+		columns = append(columns, "ts_headline('"+m.config+"', "+expression+", to_tsquery('"+m.config+"', ?), '"+headlineOptions+"') as "+names[i])
+		args = append(args, anyQuery)
+	}
+	return strings.Join(columns, ", "), args
+}
+
+// initialsFilter сопоставляет инициалы по порядку с именем и отчеством автора, в обеих раскладках
+func initialsFilter(query textsearch.Query) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		columns := []string{"a.first_name", "a.second_name"}
+		for i, initial := range query.Initials {
+			if i >= len(columns) {
+				break
+			}
+			var conditions []string
+			var patterns []any
+			for _, variant := range textsearch.InitialVariants(initial) {
+				conditions = append(conditions, columns[i]+" ilike ?")
+				patterns = append(patterns, variant+"%")
+			}
+			db = db.Where("("+strings.Join(conditions, " or ")+")", patterns...)
+		}
+		return db
+	}
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"gin_main/internal/repositories/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MovementInfo описывает, кто и почему меняет остаток; сохраняется в журнал движения вместе с изменением
+type MovementInfo struct {
+	Reason        string
+	ActorID       uuid.UUID
+	CorrelationID string
+	Comment       string
+}
+
+// MovementTotal - итог журнала движения по причине и направлению изменения остатка
+type MovementTotal struct {
+	Reason    string
+	Direction string // in - приход, out - расход
+	Movements int64  // записей в журнале
+	Units     int64  // экземпляров по модулю
+}
+
+type StockMovementRepositoryInterface interface {
+	GetByBook(ctx context.Context, bookID uuid.UUID, from, to *time.Time, limit, offset int) ([]entities.StockMovement, int64, error) // движения книги за период, от старых к новым, и их общее количество
+	Totals(ctx context.Context) ([]MovementTotal, error)                                                                              // итоги всего журнала по причинам и направлениям
+}
+
+type stockMovementRepository struct {
+	database *gorm.DB
+}
+
+func NewStockMovementRepository(database *gorm.DB) StockMovementRepositoryInterface {
+	return &stockMovementRepository{database: database}
+}
+
+func (r *stockMovementRepository) GetByBook(ctx context.Context, bookID uuid.UUID, from, to *time.Time, limit, offset int) ([]entities.StockMovement, int64, error) {
+	query := r.database.WithContext(ctx).Model(&entities.StockMovement{}).Where("book_id = ?", bookID)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var movements []entities.StockMovement
+	if err := query.Order("created_at, id").Limit(limit).Offset(offset).Find(&movements).Error; err != nil {
+		return nil, 0, err
+	}
+	return movements, total, nil
+}
+
+func (r *stockMovementRepository) Totals(ctx context.Context) ([]MovementTotal, error) {
+	var totals []MovementTotal
+	err := r.database.WithContext(ctx).Raw(movementTotalsQuery).Scan(&totals).Error
+	return totals, err
+}
+
+// триггер запрещает менять и удалять строки журнала, поэтому итоги по нему монотонны и годятся для счётчиков
+const movementTotalsQuery = `select reason,
+	case when delta < 0 then 'out' else 'in' end as direction,
+	count(*) as movements,
+	coalesce(sum(abs(delta)), 0) as units
+from stock_movements
+group by 1, 2`
+
+// recordMovement пишет строку журнала в транзакции tx, в которой менялся остаток
+func recordMovement(tx *gorm.DB, bookID, editionID, locationID uuid.UUID, delta, locationBalance int, info MovementInfo) error {
+	var balance int
+	if err := tx.Model(&entities.StockLevel{}).Select("coalesce(sum(quantity), 0)").Where("book_id = ?", bookID).Scan(&balance).Error; err != nil {
+		return err
+	}
+	movement := entities.StockMovement{
+		BookID:          bookID,
+		EditionID:       editionID,
+		LocationID:      locationID,
+		Delta:           delta,
+		LocationBalance: locationBalance,
+		Balance:         balance,
+		Reason:          info.Reason,
+		CorrelationID:   info.CorrelationID,
+		Comment:         info.Comment,
+	}
+	if info.ActorID != uuid.Nil {
+		movement.ActorID = &info.ActorID
+	}
+	return tx.Create(&movement).Error
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"gin_main/internal/repositories/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrContributorInTrash = errors.New("book has contributors in trash")
+	ErrBookHasHistory     = errors.New("book has stock, order or purchasing history")
+)
+
+type TrashRepositoryInterface interface {
+	GetBooks(ctx context.Context) ([]entities.Book, error)     // книги в корзине вместе с участниками, последние удалённые первыми
+	GetAuthors(ctx context.Context) ([]entities.Author, error) // авторы в корзине, последние удалённые первыми
+	RestoreBook(ctx context.Context, id uuid.UUID) error       // возвращает книгу из корзины; все её участники должны быть вне корзины
+	RestoreAuthor(ctx context.Context, id uuid.UUID) error     // возвращает автора из корзины
+	PurgeBook(ctx context.Context, id uuid.UUID) error         // окончательно удаляет книгу из корзины вместе с изданиями, если на неё не ссылается история
+	PurgeAuthor(ctx context.Context, id uuid.UUID) error       // окончательно удаляет автора из корзины, если он не участвует ни в одной книге
+}
+
+type trashRepository struct {
+	database *gorm.DB
+}
+
+func NewTrashRepository(database *gorm.DB) TrashRepositoryInterface {
+	return &trashRepository{database: database}
+}
+
+func (r *trashRepository) GetBooks(ctx context.Context) ([]entities.Book, error) {
+	var books []entities.Book
+	results := r.database.WithContext(ctx).Unscoped().
+		Preload("Contributors", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Contributors.Author").
+		Where("deleted_at is not null").
+		Order("deleted_at desc, id").
+		Find(&books)
+	if results.Error != nil {
+		return nil, results.Error
+	}
+	return books, nil
+}
+
+func (r *trashRepository) GetAuthors(ctx context.Context) ([]entities.Author, error) {
+	var authors []entities.Author
+	results := r.database.WithContext(ctx).Unscoped().
+		Where("deleted_at is not null").
+		Order("deleted_at desc, id").
+		Find(&authors)
+	if results.Error != nil {
+		return nil, results.Error
+	}
+	return authors, nil
+}
+
+func (r *trashRepository) RestoreBook(ctx context.Context, id uuid.UUID) error {
+	return r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var trashed int64
+		err := tx.Model(&entities.BookContributor{}).
+			Joins("join authors on authors.id = book_contributors.author_id").
+			Where("book_contributors.book_id = ? and authors.deleted_at is not null", id).
+			Count(&trashed).Error
+		if err != nil {
+			return err
+		}
+		if trashed > 0 {
+			return ErrContributorInTrash
+		}
+		if err := restore(tx, &entities.Book{}, id); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrISBNExists
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *trashRepository) RestoreAuthor(ctx context.Context, id uuid.UUID) error {
+	return restore(r.database.WithContext(ctx), &entities.Author{}, id)
+}
+
+func (r *trashRepository) PurgeBook(ctx context.Context, id uuid.UUID) error {
+	err := purge(r.database.WithContext(ctx), &entities.Book{}, id)
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return ErrBookHasHistory
+	}
+	return err
+}
+
+func (r *trashRepository) PurgeAuthor(ctx context.Context, id uuid.UUID) error {
+	err := purge(r.database.WithContext(ctx), &entities.Author{}, id)
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return ErrAuthorHasBooks
+	}
+	return err
+}
+
+// softDelete помечает живую запись удалённой; повторное удаление записи из корзины возвращает ErrRecordNotFound
+func softDelete(tx *gorm.DB, model any, id, deletedBy uuid.UUID) error {
+	var actor *uuid.UUID
+	if deletedBy != uuid.Nil {
+		actor = &deletedBy
+	}
+	result := tx.Model(model).Where("id = ?", id).Updates(map[string]any{
+		"deleted_at": time.Now(),
+		"deleted_by": actor,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func restore(tx *gorm.DB, model any, id uuid.UUID) error {
+	result := tx.Unscoped().Model(model).
+		Where("id = ? and deleted_at is not null", id).
+		Updates(map[string]any{"deleted_at": nil, "deleted_by": nil})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// purge удаляет только записи из корзины, поэтому живую запись нельзя стереть в обход мягкого удаления
+func purge(tx *gorm.DB, model any, id uuid.UUID) error {
+	result := tx.Unscoped().Where("id = ? and deleted_at is not null", id).Delete(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
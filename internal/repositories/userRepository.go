@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"gin_main/internal/repositories/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrUserExists = errors.New("user with this login already exists")
+
+type UserRepositoryInterface interface {
+	Create(ctx context.Context, user entities.User) (entities.User, error)        // создаёт пользователя, логин должен быть уникальным
+	FindById(ctx context.Context, id uuid.UUID) (entities.User, error)            // найдёт пользователя по id
+	FindByLogin(ctx context.Context, login string) (entities.User, error)         // найдёт пользователя по логину
+	GetAll(ctx context.Context) ([]entities.User, error)                          // возвращает всех пользователей
+	SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error           // блокирует или разблокирует пользователя; блокировка отзывает выданные токены
+	SetPasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error // заменяет хэш пароля и отзывает выданные токены
+	SetLastLogin(ctx context.Context, id uuid.UUID, lastLoginAt time.Time) error  // фиксирует время успешного входа
+}
+
+type userRepository struct {
+	database *gorm.DB
+}
+
+func NewUserRepository(database *gorm.DB) UserRepositoryInterface {
+	return &userRepository{database: database}
+}
+
+func (r *userRepository) Create(ctx context.Context, user entities.User) (entities.User, error) {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if result := r.database.WithContext(ctx).Create(&user); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return entities.User{}, ErrUserExists
+		}
+		return entities.User{}, result.Error
+	}
+	return user, nil
+}
+
+func (r *userRepository) FindById(ctx context.Context, id uuid.UUID) (entities.User, error) {
+	var user entities.User
+	if result := r.database.WithContext(ctx).First(&user, "id = ?", id); result.Error != nil {
+		return entities.User{}, result.Error
+	}
+	return user, nil
+}
+
+func (r *userRepository) FindByLogin(ctx context.Context, login string) (entities.User, error) {
+	var user entities.User
+	if result := r.database.WithContext(ctx).First(&user, "login = ?", login); result.Error != nil {
+		return entities.User{}, result.Error
+	}
+	return user, nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]entities.User, error) {
+	var users []entities.User
+	if results := r.database.WithContext(ctx).Order("login").Find(&users); results.Error != nil {
+		return nil, results.Error
+	}
+	return users, nil
+}
+
+func (r *userRepository) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	if !disabled {
+		return r.updateColumns(ctx, id, map[string]any{"disabled": false})
+	}
+	return r.updateColumns(ctx, id, map[string]any{"disabled": true, "tokens_valid_after": gorm.Expr("now()")})
+}
+
+func (r *userRepository) SetPasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return r.updateColumns(ctx, id, map[string]any{"password_hash": passwordHash, "tokens_valid_after": gorm.Expr("now()")})
+}
+
+func (r *userRepository) SetLastLogin(ctx context.Context, id uuid.UUID, lastLoginAt time.Time) error {
+	return r.updateColumns(ctx, id, map[string]any{"last_login_at": lastLoginAt})
+}
+
+func (r *userRepository) updateColumns(ctx context.Context, id uuid.UUID, columns map[string]any) error {
+	result := r.database.WithContext(ctx).Model(&entities.User{}).Where("id = ?", id).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
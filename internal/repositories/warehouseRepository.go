@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"gin_main/internal/repositories/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWarehouseExists   = errors.New("warehouse with this code already exists")
+	ErrLocationExists    = errors.New("location with this address already exists")
+	ErrInsufficientStock = errors.New("not enough stock in source location")
+)
+
+type WarehouseRepositoryInterface interface {
+	CreateWarehouse(ctx context.Context, warehouse entities.Warehouse) (entities.Warehouse, error)                                   // создаёт склад с уникальным кодом
+	GetAllWarehouses(ctx context.Context) ([]entities.Warehouse, error)                                                              // возвращает все склады
+	FindWarehouseById(ctx context.Context, id uuid.UUID) (entities.Warehouse, error)                                                 // найдёт склад по id
+	CreateLocation(ctx context.Context, location entities.Location) (entities.Location, error)                                       // создаёт ячейку (ряд/полка/место) на складе
+	GetLocations(ctx context.Context, warehouseID uuid.UUID) ([]entities.Location, error)                                            // возвращает все ячейки склада
+	GetBookStock(ctx context.Context, bookID uuid.UUID) ([]entities.StockLevel, error)                                               // остатки изданий книги по всем ячейкам всех складов
+	GetWarehouseStock(ctx context.Context, warehouseID uuid.UUID) ([]entities.StockLevel, error)                                     // все ненулевые остатки на складе
+	MoveStock(ctx context.Context, bookID, editionID, fromLocationID, toLocationID uuid.UUID, quantity int, info MovementInfo) error // атомарно переносит остаток издания между ячейками с записью в журнал; uuid.Nil - единственное издание книги
+}
+
+type warehouseRepository struct {
+	database *gorm.DB
+}
+
+func NewWarehouseRepository(database *gorm.DB) WarehouseRepositoryInterface {
+	return &warehouseRepository{database: database}
+}
+
+func (r *warehouseRepository) CreateWarehouse(ctx context.Context, warehouse entities.Warehouse) (entities.Warehouse, error) {
+	if warehouse.ID == uuid.Nil {
+		warehouse.ID = uuid.New()
+	}
+	if result := r.database.WithContext(ctx).Create(&warehouse); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return entities.Warehouse{}, ErrWarehouseExists
+		}
+		return entities.Warehouse{}, result.Error
+	}
+	return warehouse, nil
+}
+
+func (r *warehouseRepository) GetAllWarehouses(ctx context.Context) ([]entities.Warehouse, error) {
+	var warehouses []entities.Warehouse
+	if results := r.database.WithContext(ctx).Order("code").Find(&warehouses); results.Error != nil {
+		return nil, results.Error
+	}
+	return warehouses, nil
+}
+
+func (r *warehouseRepository) FindWarehouseById(ctx context.Context, id uuid.UUID) (entities.Warehouse, error) {
+	var warehouse entities.Warehouse
+	if result := r.database.WithContext(ctx).First(&warehouse, "id = ?", id); result.Error != nil {
+		return entities.Warehouse{}, result.Error
+	}
+	return warehouse, nil
+}
+
+func (r *warehouseRepository) CreateLocation(ctx context.Context, location entities.Location) (entities.Location, error) {
+	if location.ID == uuid.Nil {
+		location.ID = uuid.New()
+	}
+	if result := r.database.WithContext(ctx).Omit("Warehouse").Create(&location); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return entities.Location{}, ErrLocationExists
+		}
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return entities.Location{}, gorm.ErrRecordNotFound
+		}
+		return entities.Location{}, result.Error
+	}
+	return location, nil
+}
+
+func (r *warehouseRepository) GetLocations(ctx context.Context, warehouseID uuid.UUID) ([]entities.Location, error) {
+	var locations []entities.Location
+	if results := r.database.WithContext(ctx).Where("warehouse_id = ?", warehouseID).Order("aisle, shelf, bin").Find(&locations); results.Error != nil {
+		return nil, results.Error
+	}
+	return locations, nil
+}
+
+func (r *warehouseRepository) GetBookStock(ctx context.Context, bookID uuid.UUID) ([]entities.StockLevel, error) {
+	var stock []entities.StockLevel
+	results := r.database.WithContext(ctx).Preload("Location.Warehouse").
+		Where("book_id = ? and quantity > 0", bookID).
+		Find(&stock)
+	if results.Error != nil {
+		return nil, results.Error
+	}
+	return stock, nil
+}
+
+func (r *warehouseRepository) GetWarehouseStock(ctx context.Context, warehouseID uuid.UUID) ([]entities.StockLevel, error) {
+	var stock []entities.StockLevel
+	results := r.database.WithContext(ctx).Preload("Location.Warehouse").
+		Joins("join locations l on l.id = stock_levels.location_id").
+		Where("l.warehouse_id = ? and stock_levels.quantity > 0", warehouseID).
+		Order("l.aisle, l.shelf, l.bin").
+		Find(&stock)
+	if results.Error != nil {
+		return nil, results.Error
+	}
+	return stock, nil
+}
+
+func (r *warehouseRepository) MoveStock(ctx context.Context, bookID, editionID, fromLocationID, toLocationID uuid.UUID, quantity int, info MovementInfo) error {
+	return r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		editionID, err := resolveEdition(tx, bookID, editionID)
+		if err != nil {
+			return err
+		}
+		// строки блокируются в порядке id ячеек, чтобы встречные перемещения не взаимоблокировались
+		var locked []entities.StockLevel
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("edition_id = ? and location_id in ?", editionID, []uuid.UUID{fromLocationID, toLocationID}).
+			Order("location_id").
+			Find(&locked).Error
+		if err != nil {
+			return err
+		}
+		available := 0
+		for _, stock := range locked {
+			if stock.LocationID == fromLocationID {
+				available = stock.Quantity
+			}
+		}
+		if available < quantity {
+			return ErrInsufficientStock
+		}
+		var toQuantity int
+		if err := tx.Raw(upsertStockLevel, bookID, editionID, toLocationID, quantity).Scan(&toQuantity).Error; err != nil {
+			if errors.Is(err, gorm.ErrForeignKeyViolated) {
+				return gorm.ErrRecordNotFound
+			}
+			return err
+		}
+		if err := tx.Model(&entities.StockLevel{}).
+			Where("edition_id = ? and location_id = ?", editionID, fromLocationID).
+			Update("quantity", gorm.Expr("quantity - ?", quantity)).Error; err != nil {
+			return err
+		}
+		info.Reason = entities.MovementReasonTransfer
+		if err := recordMovement(tx, bookID, editionID, fromLocationID, -quantity, available-quantity, info); err != nil {
+			return err
+		}
+		return recordMovement(tx, bookID, editionID, toLocationID, quantity, toQuantity, info)
+	})
+}
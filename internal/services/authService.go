@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"gin_main/internal/jwt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/httpserver/middlewares"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuthServiceInterface interface {
+	middlewares.Authenticator
+	Login(ctx context.Context, request models.LoginRequest) (models.TokenResponse, error)
+	Refresh(ctx context.Context, request models.RefreshRequest) (models.TokenResponse, error)
+	Logout(ctx context.Context, request models.RefreshRequest) error
+}
+
+type authService struct {
+	jwtHelper     jwt.JWTHelperInterface
+	userService   UserServiceInterface
+	revokedTokens repositories.RevokedTokenRepositoryInterface
+}
+
+func NewAuthService(jwtHelper jwt.JWTHelperInterface, userService UserServiceInterface, revokedTokens repositories.RevokedTokenRepositoryInterface) AuthServiceInterface {
+	return &authService{
+		jwtHelper:     jwtHelper,
+		userService:   userService,
+		revokedTokens: revokedTokens,
+	}
+}
+
+func (r *authService) Login(ctx context.Context, request models.LoginRequest) (models.TokenResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	user, inError := r.userService.CheckCredentials(ctx, request.Login, request.Password)
+	if inError != nil {
+		return models.TokenResponse{}, inError
+	}
+	return r.issueTokens(user)
+}
+
+func (r *authService) Refresh(ctx context.Context, request models.RefreshRequest) (models.TokenResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Refresh")
+	defer span.End()
+
+	// refresh токен одноразовый: при обмене он отзывается, повторное предъявление отклоняется
+	claims, revoked, inError := r.revoke(ctx, request.RefreshToken)
+	if inError != nil {
+		return models.TokenResponse{}, inError
+	}
+	if !revoked {
+		return models.TokenResponse{}, invalidRefreshToken()
+	}
+	// роль перечитывается из хранилища, чтобы её изменение вступало в силу при следующем обновлении
+	user, inError := r.userService.FindById(ctx, uuid.MustParse(claims.Subject))
+	if inError != nil {
+		if apperrors.Is(inError, apperrors.KindNotFound) {
+			return models.TokenResponse{}, invalidRefreshToken()
+		}
+		return models.TokenResponse{}, inError
+	}
+	if user.Disabled {
+		return models.TokenResponse{}, userDisabled()
+	}
+	if issuedBeforeCutoff(user, claims) {
+		return models.TokenResponse{}, invalidRefreshToken()
+	}
+	return r.issueTokens(user)
+}
+
+func (r *authService) Logout(ctx context.Context, request models.RefreshRequest) error {
+	ctx, span := tracer.Start(ctx, "AuthService.Logout")
+	defer span.End()
+
+	// повторный выход с тем же токеном не считается ошибкой
+	_, _, inError := r.revoke(ctx, request.RefreshToken)
+	return inError
+}
+
+// Authenticate проверяет подпись access токена и каждый раз перечитывает пользователя:
+// блокировка и смена роли должны действовать сразу, а не после истечения уже выданного токена
+func (r *authService) Authenticate(ctx context.Context, token string) (*middlewares.Identity, error) {
+	claims, err := r.jwtHelper.ParseAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	user, inError := r.userService.FindById(ctx, uuid.MustParse(claims.Subject))
+	if inError != nil {
+		if apperrors.Is(inError, apperrors.KindNotFound) {
+			return nil, invalidToken()
+		}
+		return nil, inError
+	}
+	if user.Disabled {
+		return nil, userDisabled()
+	}
+	if issuedBeforeCutoff(user, claims) {
+		return nil, invalidToken()
+	}
+	// логин и роль берутся из базы: понижение в правах действует сразу, как и блокировка
+	return &middlewares.Identity{
+		UserID: user.ID,
+		Login:  user.Login,
+		Role:   user.Role,
+	}, nil
+}
+
+func (r *authService) issueTokens(user models.User) (models.TokenResponse, error) {
+	tokens, err := r.jwtHelper.GenerateTokens(user.ID, user.Login, user.Role)
+	if err != nil {
+		return models.TokenResponse{}, apperrors.Internal(err)
+	}
+	return models.TokenResponse{
+		TokenType:        "Bearer",
+		AccessToken:      tokens.AccessToken,
+		AccessExpiresAt:  tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}, nil
+}
+
+// revoke проверяет refresh токен и отзывает его; revoked = false, если токен уже был отозван
+func (r *authService) revoke(ctx context.Context, refreshToken string) (*jwt.Claims, bool, error) {
+	claims, err := r.jwtHelper.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, false, invalidRefreshToken()
+	}
+	// subject уже проверен при разборе токена
+	revoked, err := r.revokedTokens.Revoke(ctx, claims.ID, uuid.MustParse(claims.Subject), claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, false, apperrors.Internal(err)
+	}
+	return claims, revoked, nil
+}
+
+// issuedBeforeCutoff сообщает, выпущен ли токен до последней смены пароля или блокировки пользователя.
+// iat хранится с точностью до секунды, поэтому и отсечка округляется вниз: иначе токен, выданный
+// сразу после смены пароля, отклонялся бы до конца этой секунды
+func issuedBeforeCutoff(user models.User, claims *jwt.Claims) bool {
+	if user.TokensValidAfter == nil {
+		return false
+	}
+	if claims.IssuedAt == nil {
+		return true
+	}
+	return claims.IssuedAt.Time.Before(user.TokensValidAfter.Truncate(time.Second))
+}
+
+func invalidToken() error {
+	return apperrors.Unauthorized("invalid_token", "invalid or expired token")
+}
+
+func invalidRefreshToken() error {
+	return apperrors.Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+}
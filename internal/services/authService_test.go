@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"gin_main/config"
+	"gin_main/internal/jwt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepository хранит пользователей в памяти теста
+type fakeUserRepository struct {
+	users map[uuid.UUID]entities.User
+}
+
+func newFakeUserRepository(users ...entities.User) *fakeUserRepository {
+	repo := &fakeUserRepository{users: make(map[uuid.UUID]entities.User)}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (f *fakeUserRepository) Create(ctx context.Context, user entities.User) (entities.User, error) {
+	user.ID = uuid.New()
+	f.users[user.ID] = user
+	return user, nil
+}
+
+func (f *fakeUserRepository) FindById(ctx context.Context, id uuid.UUID) (entities.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return entities.User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUserRepository) FindByLogin(ctx context.Context, login string) (entities.User, error) {
+	for _, user := range f.users {
+		if user.Login == login {
+			return user, nil
+		}
+	}
+	return entities.User{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUserRepository) GetAll(ctx context.Context) ([]entities.User, error) {
+	users := make([]entities.User, 0, len(f.users))
+	for _, user := range f.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (f *fakeUserRepository) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	user, ok := f.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	user.Disabled = disabled
+	if disabled {
+		now := time.Now()
+		user.TokensValidAfter = &now
+	}
+	f.users[id] = user
+	return nil
+}
+
+func (f *fakeUserRepository) SetPasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	user, ok := f.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	user.PasswordHash = passwordHash
+	now := time.Now()
+	user.TokensValidAfter = &now
+	f.users[id] = user
+	return nil
+}
+
+func (f *fakeUserRepository) SetLastLogin(ctx context.Context, id uuid.UUID, lastLoginAt time.Time) error {
+	user := f.users[id]
+	user.LastLoginAt = &lastLoginAt
+	f.users[id] = user
+	return nil
+}
+
+// fakeRevokedTokenRepository - общее для всех инстансов хранилище отозванных токенов
+type fakeRevokedTokenRepository struct {
+	revoked map[string]time.Time
+}
+
+func (f *fakeRevokedTokenRepository) Revoke(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) (bool, error) {
+	if _, exists := f.revoked[tokenID]; exists {
+		return false, nil
+	}
+	f.revoked[tokenID] = expiresAt
+	return true, nil
+}
+
+func newTestJWTHelper() jwt.JWTHelperInterface {
+	cfg := &config.Config{App: "test"}
+	cfg.Auth.AccessSecret, cfg.Auth.RefreshSecret = "access", "refresh"
+	cfg.Auth.AccessTTL, cfg.Auth.RefreshTTL = time.Minute, time.Hour
+	return jwt.NewJWTHelper(cfg)
+}
+
+func TestAuthenticateRejectsDisabledUser(t *testing.T) {
+	user := entities.User{ID: uuid.New(), Login: "clerk", Role: models.RoleClerk}
+	users := newFakeUserRepository(user)
+	jwtHelper := newTestJWTHelper()
+	service := NewAuthService(jwtHelper, NewUserService(users), &fakeRevokedTokenRepository{revoked: map[string]time.Time{}})
+	tokens, err := jwtHelper.GenerateTokens(user.ID, user.Login, user.Role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := service.Authenticate(context.Background(), tokens.AccessToken)
+	if err != nil || identity.UserID != user.ID || identity.Role != models.RoleClerk {
+		t.Fatalf("Authenticate = %+v, %v, want the clerk", identity, err)
+	}
+
+	_ = users.SetDisabled(context.Background(), user.ID, true)
+	_, err = service.Authenticate(context.Background(), tokens.AccessToken)
+	if inError := apperrors.From(err); inError.Code != "user_disabled" || apperrors.Status(inError.Kind) != http.StatusForbidden {
+		t.Errorf("disabled user: error = %v, want 403 user_disabled", err)
+	}
+
+	delete(users.users, user.ID)
+	if _, err = service.Authenticate(context.Background(), tokens.AccessToken); !apperrors.Is(err, apperrors.KindUnauthorized) {
+		t.Errorf("deleted user: error = %v, want unauthorized", err)
+	}
+}
+
+func TestAuthenticateTakesRoleFromDatabase(t *testing.T) {
+	user := entities.User{ID: uuid.New(), Login: "manager", Role: models.RoleManager}
+	users := newFakeUserRepository(user)
+	jwtHelper := newTestJWTHelper()
+	service := NewAuthService(jwtHelper, NewUserService(users), &fakeRevokedTokenRepository{revoked: map[string]time.Time{}})
+	tokens, err := jwtHelper.GenerateTokens(user.ID, user.Login, user.Role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// после выдачи токена пользователя переименовали и понизили до читателя
+	user.Login, user.Role = "viewer", models.RoleViewer
+	users.users[user.ID] = user
+	identity, err := service.Authenticate(context.Background(), tokens.AccessToken)
+	if err != nil || identity.Login != "viewer" || identity.Role != models.RoleViewer {
+		t.Errorf("Authenticate = %+v, %v, want login and role from the database", identity, err)
+	}
+}
+
+func TestTokensIssuedBeforeCutoffAreRejected(t *testing.T) {
+	user := entities.User{ID: uuid.New(), Login: "clerk", Role: models.RoleClerk}
+	users := newFakeUserRepository(user)
+	jwtHelper := newTestJWTHelper()
+	service := NewAuthService(jwtHelper, NewUserService(users), &fakeRevokedTokenRepository{revoked: map[string]time.Time{}})
+	tokens, err := jwtHelper.GenerateTokens(user.ID, user.Login, user.Role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// пароль сброшен в следующей секунде после выдачи токенов
+	cutoff := time.Now().Add(time.Second)
+	user.TokensValidAfter = &cutoff
+	users.users[user.ID] = user
+	if _, err = service.Authenticate(context.Background(), tokens.AccessToken); apperrors.From(err).Code != "invalid_token" {
+		t.Errorf("access token before cutoff: error = %v, want invalid_token", err)
+	}
+	if _, err = service.Refresh(context.Background(), models.RefreshRequest{RefreshToken: tokens.RefreshToken}); apperrors.From(err).Code != "invalid_refresh_token" {
+		t.Errorf("refresh token before cutoff: error = %v, want invalid_refresh_token", err)
+	}
+}
+
+func TestIssuedBeforeCutoff(t *testing.T) {
+	issuedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	claims := &jwt.Claims{RegisteredClaims: jwtlib.RegisteredClaims{IssuedAt: jwtlib.NewNumericDate(issuedAt)}}
+	at := func(offset time.Duration) *time.Time {
+		cutoff := issuedAt.Add(offset)
+		return &cutoff
+	}
+	cases := []struct {
+		name   string
+		cutoff *time.Time
+		want   bool
+	}{
+		{"no cutoff", nil, false},
+		{"cutoff before issue", at(-time.Minute), false},
+		{"cutoff in the same second", at(700 * time.Millisecond), false},
+		{"cutoff in a later second", at(1200 * time.Millisecond), true},
+	}
+	for _, tc := range cases {
+		if got := issuedBeforeCutoff(models.User{TokensValidAfter: tc.cutoff}, claims); got != tc.want {
+			t.Errorf("%s: issuedBeforeCutoff = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if !issuedBeforeCutoff(models.User{TokensValidAfter: at(0)}, &jwt.Claims{}) {
+		t.Error("token without iat must be rejected once a cutoff is set")
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+type AuthorServiceInterface interface {
+	Create(ctx context.Context, author models.CreateOrUpdateAuthorRequest) (models.CreateAuthorResponse, error)
+	Update(ctx context.Context, id uuid.UUID, author models.CreateOrUpdateAuthorRequest, version int) (int, error)
+	FindById(ctx context.Context, id uuid.UUID) (models.Author, error)
+	GetAll(ctx context.Context) ([]models.Author, error)
+	Delete(ctx context.Context, id uuid.UUID, actor models.Actor) error
+	GetBooks(ctx context.Context, id uuid.UUID) ([]models.Book, error)
+}
+
+type authorService struct {
+	authorRepo repositories.AuthorRepositoryInterface
+}
+
+func NewAuthorService(authorRepo repositories.AuthorRepositoryInterface) AuthorServiceInterface {
+	return &authorService{authorRepo: authorRepo}
+}
+
+func (r *authorService) Create(ctx context.Context, author models.CreateOrUpdateAuthorRequest) (models.CreateAuthorResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.Create")
+	defer span.End()
+
+	var err error
+	var authorEntity entities.Author
+	if err = copier.Copy(&authorEntity, &author); err != nil {
+		return models.CreateAuthorResponse{}, apperrors.Internal(err)
+	}
+	newAuthorEntity, err := r.authorRepo.Create(ctx, authorEntity)
+	if err != nil {
+		return models.CreateAuthorResponse{}, apperrors.Internal(err)
+	}
+	return models.CreateAuthorResponse{ID: newAuthorEntity.ID}, nil
+}
+
+func (r *authorService) Update(ctx context.Context, id uuid.UUID, author models.CreateOrUpdateAuthorRequest, version int) (int, error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.Update")
+	defer span.End()
+
+	var err error
+	var authorEntity entities.Author
+	if err = copier.Copy(&authorEntity, &author); err != nil {
+		return 0, apperrors.Internal(err)
+	}
+	authorEntity.ID = id
+	newVersion, err := r.authorRepo.Update(ctx, authorEntity, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, authorNotFound(id)
+		}
+		if errors.Is(err, repositories.ErrVersionMismatch) {
+			current, inError := r.FindById(ctx, id)
+			if inError != nil {
+				return 0, inError
+			}
+			return 0, versionMismatch("author", id, current)
+		}
+		return 0, apperrors.Internal(err)
+	}
+	return newVersion, nil
+}
+
+func (r *authorService) FindById(ctx context.Context, id uuid.UUID) (models.Author, error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.FindById")
+	defer span.End()
+
+	authorFound, err := r.authorRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Author{}, authorNotFound(id)
+		}
+		return models.Author{}, apperrors.Internal(err)
+	}
+	var authorResult models.Author
+	if err = copier.Copy(&authorResult, &authorFound); err != nil {
+		return models.Author{}, apperrors.Internal(err)
+	}
+	authorResult.FullName = fullName(authorResult)
+	return authorResult, nil
+}
+
+func (r *authorService) GetAll(ctx context.Context) ([]models.Author, error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.GetAll")
+	defer span.End()
+
+	authorsEntities, err := r.authorRepo.GetAll(ctx)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	var authors []models.Author
+	if err = copier.Copy(&authors, &authorsEntities); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	for i := range authors {
+		authors[i].FullName = fullName(authors[i])
+	}
+	return authors, nil
+}
+
+func (r *authorService) Delete(ctx context.Context, id uuid.UUID, actor models.Actor) error {
+	ctx, span := tracer.Start(ctx, "AuthorService.Delete")
+	defer span.End()
+
+	if err := r.authorRepo.Delete(ctx, id, actor.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return authorNotFound(id)
+		}
+		if errors.Is(err, repositories.ErrAuthorHasBooks) {
+			return apperrors.Conflict("author_has_books", fmt.Sprintf("author with id = %s still has books", id.String()))
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (r *authorService) GetBooks(ctx context.Context, id uuid.UUID) ([]models.Book, error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.GetBooks")
+	defer span.End()
+
+	if _, inError := r.FindById(ctx, id); inError != nil {
+		return nil, inError
+	}
+	booksEntities, err := r.authorRepo.GetBooks(ctx, id)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	books := make([]models.Book, 0, len(booksEntities))
+	for _, bookEntity := range booksEntities {
+		book, err := toBookModel(bookEntity)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		books = append(books, book)
+	}
+	return books, nil
+}
+
+func authorNotFound(id uuid.UUID) error {
+	return apperrors.NotFound("author_not_found", fmt.Sprintf("author with id = %s not found", id.String()))
+}
+
+// fullName собирает ФИО в порядке "Фамилия Имя Отчество", пропуская пустые части
+func fullName(author models.Author) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{author.Surname, author.FirstName, author.SecondName} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
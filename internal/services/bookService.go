@@ -1,27 +1,33 @@
 package services
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"fmt"
 	"gin_main/internal/models"
 	"gin_main/internal/repositories"
 	"gin_main/internal/repositories/entities"
-	"net/http"
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/isbn"
+	"gin_main/pkg/pagination"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
 )
 
 type BookServiceInterface interface {
-	Create(book models.CreateOrUpdateBookRequest) (models.CreateBookResponse, *models.ErrorResponse)
-	Update(book models.CreateOrUpdateBookRequest) *models.ErrorResponse
-	FindById(id uuid.UUID) (models.Book, *models.ErrorResponse)
-	FindByParameters(title, author string, yearOfWriting, yearOfBirth *time.Time) ([]models.Book, *models.ErrorResponse)
-	GetAll() ([]models.Book, *models.ErrorResponse)
-	ChangeQuantity(book models.ChangeBookQuantityRequest) (models.ChangeBookQuantityResponse, *models.ErrorResponse)
+	Create(ctx context.Context, book models.CreateOrUpdateBookRequest) (models.CreateBookResponse, error)
+	Update(ctx context.Context, id uuid.UUID, book models.CreateOrUpdateBookRequest, version int) (int, error)
+	FindById(ctx context.Context, id uuid.UUID) (models.Book, error)
+	FindByISBN(ctx context.Context, raw string) (models.Book, error)
+	FindByParameters(ctx context.Context, title, author string, yearOfWriting, yearOfBirth *time.Time) ([]models.Book, error)
+	List(ctx context.Context, request models.ListBooksRequest) (pagination.Page[models.Book], error)
+	Export(ctx context.Context, visit func(models.Book) error) error
+	ChangeQuantity(ctx context.Context, id uuid.UUID, book models.ChangeBookQuantityRequest, actor models.Actor) (models.ChangeBookQuantityResponse, error)
+	Delete(ctx context.Context, id uuid.UUID, actor models.Actor) error
 }
 
 type bookService struct {
@@ -32,132 +38,368 @@ func NewBookService(bookRepo repositories.BookRepositoryInterface) BookServiceIn
 	return &bookService{bookRepo: bookRepo}
 }
 
-func (r *bookService) Create(book models.CreateOrUpdateBookRequest) (models.CreateBookResponse, *models.ErrorResponse) {
+func (r *bookService) Create(ctx context.Context, book models.CreateOrUpdateBookRequest) (models.CreateBookResponse, error) {
+	ctx, span := tracer.Start(ctx, "BookService.Create")
+	defer span.End()
+
 	var err error
 	var bookEntity entities.Book
 	if err = copier.Copy(&bookEntity, &book); err != nil {
-		return models.CreateBookResponse{}, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
-		}
+		return models.CreateBookResponse{}, apperrors.Internal(err)
+	}
+	if bookEntity.ISBN, err = normalizeISBN(book.ISBN); err != nil {
+		return models.CreateBookResponse{}, invalidISBN(err)
+	}
+	var inError error
+	if bookEntity.Contributors, inError = bookContributors(book); inError != nil {
+		return models.CreateBookResponse{}, inError
 	}
-	newBookEntity, err := r.bookRepo.Create(bookEntity)
+	if len(bookEntity.Contributors) == 0 {
+		return models.CreateBookResponse{}, apperrors.Validation("contributor_required", "book must have at least one contributor").WithField("contributors", "must contain at least one contributor")
+	}
+	newBookEntity, err := r.bookRepo.Create(ctx, bookEntity)
 	if err != nil {
-		return models.CreateBookResponse{}, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+		if errors.Is(err, repositories.ErrISBNExists) {
+			return models.CreateBookResponse{}, isbnExists(*bookEntity.ISBN)
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return models.CreateBookResponse{}, contributorNotFound()
 		}
+		return models.CreateBookResponse{}, apperrors.Internal(err)
 	}
 	var bookResponse models.CreateBookResponse
 	if err = copier.Copy(&bookResponse, &newBookEntity); err != nil {
-		return models.CreateBookResponse{}, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
-		}
+		return models.CreateBookResponse{}, apperrors.Internal(err)
 	}
 	return bookResponse, nil
 }
 
-func (r *bookService) Update(book models.CreateOrUpdateBookRequest) *models.ErrorResponse {
+func (r *bookService) Update(ctx context.Context, id uuid.UUID, book models.CreateOrUpdateBookRequest, version int) (int, error) {
+	ctx, span := tracer.Start(ctx, "BookService.Update")
+	defer span.End()
+
 	var err error
 	var bookEntity entities.Book
 	if err = copier.Copy(&bookEntity, &book); err != nil {
-		return &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
-		}
+		return 0, apperrors.Internal(err)
+	}
+	bookEntity.ID = id
+	bookEntity.Editions = nil // издания меняются через /books/:id/editions
+	if bookEntity.ISBN, err = normalizeISBN(book.ISBN); err != nil {
+		return 0, invalidISBN(err)
+	}
+	var inError error
+	if bookEntity.Contributors, inError = bookContributors(book); inError != nil {
+		return 0, inError
 	}
-	if err := r.bookRepo.Update(bookEntity); err != nil {
-		return &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+	newVersion, err := r.bookRepo.Update(ctx, bookEntity, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, bookNotFound(id)
+		}
+		if errors.Is(err, repositories.ErrVersionMismatch) {
+			current, inError := r.FindById(ctx, id)
+			if inError != nil {
+				return 0, inError
+			}
+			return 0, versionMismatch("book", id, current)
 		}
+		if errors.Is(err, repositories.ErrISBNExists) {
+			return 0, isbnExists(*bookEntity.ISBN)
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return 0, contributorNotFound()
+		}
+		return 0, apperrors.Internal(err)
 	}
-	return nil
+	return newVersion, nil
 }
 
-func (r *bookService) FindById(id uuid.UUID) (models.Book, *models.ErrorResponse) {
+func (r *bookService) FindById(ctx context.Context, id uuid.UUID) (models.Book, error) {
+	ctx, span := tracer.Start(ctx, "BookService.FindById")
+	defer span.End()
+
 	var err error
-	bookFound, err := r.bookRepo.FindById(id)
+	bookFound, err := r.bookRepo.FindById(ctx, id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return models.Book{}, &models.ErrorResponse{
-				Code:    http.StatusNotFound,
-				Message: fmt.Sprintf("user with id = %s not found", id.String()),
-			}
-		}
-		return models.Book{}, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Book{}, bookNotFound(id)
 		}
+		return models.Book{}, apperrors.Internal(err)
 	}
-	var bookResult models.Book
-	if err = copier.Copy(&bookResult, &bookFound); err != nil {
-		return models.Book{}, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
-		}
+	bookResult, err := toBookModel(bookFound)
+	if err != nil {
+		return models.Book{}, apperrors.Internal(err)
 	}
 	return bookResult, nil
 }
 
-func (r *bookService) FindByParameters(title, author string, yearOfWriting, yearOfBirth *time.Time) ([]models.Book, *models.ErrorResponse) {
-	books, err := r.bookRepo.FindByParameters(title, author, yearOfWriting, yearOfBirth)
+// FindByISBN принимает строку как есть: ISBN с дефисами или EAN-13 со сканера на упаковке
+func (r *bookService) FindByISBN(ctx context.Context, raw string) (models.Book, error) {
+	ctx, span := tracer.Start(ctx, "BookService.FindByISBN")
+	defer span.End()
+
+	code, err := isbn.NormalizeScanned(raw)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return []models.Book{}, &models.ErrorResponse{
-				Code:    http.StatusNotFound,
-				Message: "no one user found",
-			}
-		}
-		return nil, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+		return models.Book{}, invalidISBN(err)
+	}
+	bookFound, err := r.bookRepo.FindByISBN(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Book{}, apperrors.NotFound("book_not_found", fmt.Sprintf("book with isbn = %s not found", code))
 		}
+		return models.Book{}, apperrors.Internal(err)
 	}
-	var booksResult []models.Book
-	if err = copier.Copy(&booksResult, &books); err != nil {
-		return nil, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+	bookResult, err := toBookModel(bookFound)
+	if err != nil {
+		return models.Book{}, apperrors.Internal(err)
+	}
+	return bookResult, nil
+}
+
+func (r *bookService) FindByParameters(ctx context.Context, title, author string, yearOfWriting, yearOfBirth *time.Time) ([]models.Book, error) {
+	ctx, span := tracer.Start(ctx, "BookService.FindByParameters")
+	defer span.End()
+
+	books, err := r.bookRepo.FindByParameters(ctx, title, author, yearOfWriting, yearOfBirth)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	booksResult := make([]models.Book, 0, len(books))
+	for _, book := range books {
+		bookResult, err := toBookModel(book)
+		if err != nil {
+			return nil, apperrors.Internal(err)
 		}
+		booksResult = append(booksResult, bookResult)
 	}
 	return booksResult, nil
 }
 
-func (r *bookService) GetAll() ([]models.Book, *models.ErrorResponse) {
-	var err error
-	booksEntities, err := r.bookRepo.GetAll()
+func (r *bookService) List(ctx context.Context, request models.ListBooksRequest) (pagination.Page[models.Book], error) {
+	ctx, span := tracer.Start(ctx, "BookService.List")
+	defer span.End()
+
+	page, err := repositories.BookListSpec.Parse(request.Sort, request.After, request.Limit)
 	if err != nil {
-		return nil, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
-		}
+		return pagination.Page[models.Book]{}, invalidPage(err)
+	}
+	filter := repositories.BookFilter{MinQuantity: request.MinQuantity, MaxQuantity: request.MaxQuantity}
+	if request.AuthorID != "" {
+		authorID := uuid.MustParse(request.AuthorID) // формат проверен при разборе запроса
+		filter.AuthorID = &authorID
+	}
+	if request.WrittenFrom != "" {
+		writtenFrom, _ := time.Parse(time.DateOnly, request.WrittenFrom)
+		filter.WrittenFrom = &writtenFrom
+	}
+	if request.WrittenTo != "" {
+		writtenTo, _ := time.Parse(time.DateOnly, request.WrittenTo)
+		filter.WrittenTo = &writtenTo
 	}
-	var books []models.Book
-	if err = copier.Copy(&books, &booksEntities); err != nil {
-		return nil, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+	booksEntities, err := r.bookRepo.List(ctx, filter, page)
+	if err != nil {
+		return pagination.Page[models.Book]{}, apperrors.Internal(err)
+	}
+	entitiesPage, err := pagination.NewPage(booksEntities, page, repositories.BookSortValue)
+	if err != nil {
+		return pagination.Page[models.Book]{}, apperrors.Internal(err)
+	}
+	booksPage, err := pagination.Map(entitiesPage, toBookModel)
+	if err != nil {
+		return pagination.Page[models.Book]{}, apperrors.Internal(err)
+	}
+	return booksPage, nil
+}
+
+// Export передаёт visit книги по одной по мере чтения из БД; отмена ctx прерывает запрос
+func (r *bookService) Export(ctx context.Context, visit func(models.Book) error) error {
+	ctx, span := tracer.Start(ctx, "BookService.Export")
+	defer span.End()
+
+	err := r.bookRepo.Export(ctx, func(book entities.Book) error {
+		bookResult, err := toBookModel(book)
+		if err != nil {
+			return err
 		}
+		return visit(bookResult)
+	})
+	if err != nil {
+		return apperrors.Internal(err)
 	}
-	return books, nil
+	return nil
 }
 
-func (r *bookService) ChangeQuantity(book models.ChangeBookQuantityRequest) (models.ChangeBookQuantityResponse, *models.ErrorResponse) {
+func (r *bookService) ChangeQuantity(ctx context.Context, id uuid.UUID, book models.ChangeBookQuantityRequest, actor models.Actor) (models.ChangeBookQuantityResponse, error) {
+	ctx, span := tracer.Start(ctx, "BookService.ChangeQuantity")
+	defer span.End()
+
 	var err error
-	newQuantity, err := r.bookRepo.ChangeQuantity(book.ID, book.Quantity)
+	if inError := validateReasonSign(book.Reason, book.Quantity); inError != nil {
+		return models.ChangeBookQuantityResponse{}, inError
+	}
+	locationQuantity, newQuantity, err := r.bookRepo.ChangeQuantity(ctx, id, book.EditionID, book.LocationID, book.Quantity, repositories.MovementInfo{
+		Reason:        book.Reason,
+		ActorID:       actor.UserID,
+		CorrelationID: actor.CorrelationID,
+		Comment:       book.Comment,
+	})
 	if err != nil {
-		if strings.Contains(err.Error(), "negative") {
-			return models.ChangeBookQuantityResponse{}, &models.ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: "quantity cannot be negative",
-			}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ChangeBookQuantityResponse{}, apperrors.NotFound("stock_target_not_found", "book, edition or location not found")
+		}
+		if errors.Is(err, repositories.ErrEditionRequired) {
+			return models.ChangeBookQuantityResponse{}, editionRequired()
+		}
+		if errors.Is(err, repositories.ErrStockReserved) {
+			return models.ChangeBookQuantityResponse{}, apperrors.InsufficientStock("stock_reserved", "quantity is held by active reservations")
+		}
+		if errors.Is(err, repositories.ErrNegativeStock) {
+			return models.ChangeBookQuantityResponse{}, apperrors.InsufficientStock("negative_stock", "quantity cannot be negative")
+		}
+		return models.ChangeBookQuantityResponse{}, apperrors.Internal(err)
+	}
+	return models.ChangeBookQuantityResponse{LocationQuantity: locationQuantity, Quantity: newQuantity}, nil
+}
+
+func (r *bookService) Delete(ctx context.Context, id uuid.UUID, actor models.Actor) error {
+	ctx, span := tracer.Start(ctx, "BookService.Delete")
+	defer span.End()
+
+	if err := r.bookRepo.Delete(ctx, id, actor.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return bookNotFound(id)
+		}
+		if errors.Is(err, repositories.ErrBookHasStock) {
+			return apperrors.Conflict("book_has_stock", fmt.Sprintf("book with id = %s still has stock on hand", id.String()))
+		}
+		if errors.Is(err, repositories.ErrBookReserved) {
+			return apperrors.Conflict("book_reserved", fmt.Sprintf("book with id = %s has active reservations", id.String()))
 		}
-		return models.ChangeBookQuantityResponse{}, &models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Internal Server Error",
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+// validateReasonSign проверяет, что направление изменения соответствует причине
+func validateReasonSign(reason string, quantity int) error {
+	switch reason {
+	case entities.MovementReasonReceipt, entities.MovementReasonReturn:
+		if quantity < 0 {
+			return apperrors.Validation("invalid_quantity_sign", fmt.Sprintf("quantity must be positive for reason %s", reason)).WithField("quantity", "must be positive")
+		}
+	case entities.MovementReasonSale, entities.MovementReasonDamage:
+		if quantity > 0 {
+			return apperrors.Validation("invalid_quantity_sign", fmt.Sprintf("quantity must be negative for reason %s", reason)).WithField("quantity", "must be negative")
+		}
+	}
+	return nil
+}
+
+// bookContributors переводит участников из запроса в сущности; краткая форма authorId/author означает одного автора.
+// Если участники в запросе не указаны, возвращает nil: при изменении книги её состав участников сохраняется
+func bookContributors(book models.CreateOrUpdateBookRequest) ([]entities.BookContributor, error) {
+	requested := book.Contributors
+	if len(requested) == 0 && (book.AuthorID != uuid.Nil || book.Author.Surname != "") {
+		requested = []models.ContributorRequest{{AuthorID: book.AuthorID, Author: book.Author}}
+	}
+	if len(requested) == 0 {
+		return nil, nil
+	}
+	contributors := make([]entities.BookContributor, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	for i, requestedContributor := range requested {
+		role := requestedContributor.Role
+		if role == "" {
+			role = entities.ContributorAuthor
+		}
+		contributor := entities.BookContributor{AuthorID: requestedContributor.AuthorID, Role: role}
+		if contributor.AuthorID == uuid.Nil {
+			if requestedContributor.Author.Surname == "" {
+				return nil, apperrors.Validation("contributor_author_required", fmt.Sprintf("contributor %d: authorId or author surname is required", i+1)).
+					WithField(fmt.Sprintf("contributors[%d]", i), "authorId or author.surname is required")
+			}
+			contributor.Author = entities.Author{
+				DateOfBirth: requestedContributor.Author.DateOfBirth,
+				FirstName:   requestedContributor.Author.FirstName,
+				SecondName:  requestedContributor.Author.SecondName,
+				Surname:     requestedContributor.Author.Surname,
+			}
+		} else {
+			key := contributor.AuthorID.String() + "|" + role
+			if seen[key] {
+				return nil, apperrors.Validation("duplicated_contributor", fmt.Sprintf("author %s is listed twice as %s", contributor.AuthorID, role)).
+					WithField(fmt.Sprintf("contributors[%d]", i), "author is already listed with this role")
+			}
+			seen[key] = true
 		}
+		contributors = append(contributors, contributor)
 	}
-	return models.ChangeBookQuantityResponse{Quantity: newQuantity}, nil
+	return contributors, nil
+}
+
+// toBookModel копирует книгу вместе с участниками и изданиями и собирает ФИО участников
+func toBookModel(book entities.Book) (models.Book, error) {
+	var bookResult models.Book
+	if err := copier.Copy(&bookResult, &book); err != nil {
+		return models.Book{}, err
+	}
+	if bookResult.Contributors == nil {
+		bookResult.Contributors = []models.Contributor{}
+	}
+	for i := range bookResult.Contributors {
+		bookResult.Contributors[i].Author.FullName = fullName(bookResult.Contributors[i].Author)
+	}
+	return bookResult, nil
+}
+
+// normalizeISBN приводит необязательный ISBN из запроса к ISBN-13; пустая строка означает "не задан"
+func normalizeISBN(raw string) (*string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	code, err := isbn.Normalize(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func invalidISBN(err error) error {
+	return apperrors.Validation("invalid_isbn", err.Error()).WithField("isbn", "must be a valid ISBN-10, ISBN-13 or EAN-13").Wrap(err)
+}
+
+// invalidPage указывает, какой из параметров сортировки и страницы не прошёл разбор
+func invalidPage(err error) error {
+	inError := apperrors.Validation("invalid_page", err.Error()).Wrap(err)
+	switch {
+	case errors.Is(err, pagination.ErrInvalidSort):
+		return inError.WithField("sort", "must list sortable fields without repeats")
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		return inError.WithField("after", "must be a cursor from the previous page with the same sort")
+	case errors.Is(err, pagination.ErrInvalidLimit):
+		return inError.WithField("limit", strings.TrimPrefix(err.Error(), pagination.ErrInvalidLimit.Error()+": "))
+	}
+	return inError
+}
+
+func isbnExists(code string) error {
+	return apperrors.Conflict("isbn_exists", fmt.Sprintf("book with isbn = %s already exists", code))
+}
+
+func contributorNotFound() error {
+	return apperrors.NotFound("contributor_not_found", "contributor author not found")
+}
+
+func editionRequired() error {
+	return apperrors.Validation("edition_required", "book has several editions, editionId is required").WithField("editionId", "is required")
+}
+
+// versionMismatch сообщает, что запись изменил кто-то другой, и возвращает её текущее состояние для повторного редактирования
+func versionMismatch(kind string, id uuid.UUID, current any) error {
+	return apperrors.PreconditionFailed("version_mismatch", fmt.Sprintf("%s with id = %s was modified by someone else", kind, id.String())).WithDetails(current)
+}
+
+func bookNotFound(id uuid.UUID) error {
+	return apperrors.NotFound("book_not_found", fmt.Sprintf("book with id = %s not found", id.String()))
 }
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+type EditionServiceInterface interface {
+	Create(ctx context.Context, bookID uuid.UUID, edition models.CreateOrUpdateEditionRequest) (models.CreateEditionResponse, error)
+	Update(ctx context.Context, bookID, id uuid.UUID, edition models.CreateOrUpdateEditionRequest) error
+	GetByBook(ctx context.Context, bookID uuid.UUID) ([]models.Edition, error)
+	Delete(ctx context.Context, bookID, id uuid.UUID) error
+}
+
+type editionService struct {
+	editionRepo repositories.EditionRepositoryInterface
+}
+
+func NewEditionService(editionRepo repositories.EditionRepositoryInterface) EditionServiceInterface {
+	return &editionService{editionRepo: editionRepo}
+}
+
+func (s *editionService) Create(ctx context.Context, bookID uuid.UUID, edition models.CreateOrUpdateEditionRequest) (models.CreateEditionResponse, error) {
+	ctx, span := tracer.Start(ctx, "EditionService.Create")
+	defer span.End()
+
+	editionEntity := toEditionEntity(edition)
+	editionEntity.BookID = bookID
+	newEdition, err := s.editionRepo.Create(ctx, editionEntity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CreateEditionResponse{}, bookNotFound(bookID)
+		}
+		return models.CreateEditionResponse{}, apperrors.Internal(err)
+	}
+	return models.CreateEditionResponse{ID: newEdition.ID}, nil
+}
+
+func (s *editionService) Update(ctx context.Context, bookID, id uuid.UUID, edition models.CreateOrUpdateEditionRequest) error {
+	ctx, span := tracer.Start(ctx, "EditionService.Update")
+	defer span.End()
+
+	editionEntity := toEditionEntity(edition)
+	editionEntity.ID = id
+	editionEntity.BookID = bookID
+	if err := s.editionRepo.Update(ctx, editionEntity); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return editionNotFound(bookID, id)
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (s *editionService) GetByBook(ctx context.Context, bookID uuid.UUID) ([]models.Edition, error) {
+	ctx, span := tracer.Start(ctx, "EditionService.GetByBook")
+	defer span.End()
+
+	editionsEntities, err := s.editionRepo.GetByBook(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, bookNotFound(bookID)
+		}
+		return nil, apperrors.Internal(err)
+	}
+	editions := []models.Edition{}
+	if err = copier.Copy(&editions, &editionsEntities); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return editions, nil
+}
+
+func (s *editionService) Delete(ctx context.Context, bookID, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "EditionService.Delete")
+	defer span.End()
+
+	if err := s.editionRepo.Delete(ctx, bookID, id); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return editionNotFound(bookID, id)
+		case errors.Is(err, repositories.ErrEditionHasStock):
+			return apperrors.Conflict("edition_has_history", "edition has stock history and cannot be deleted")
+		case errors.Is(err, repositories.ErrLastEdition):
+			return apperrors.Conflict("last_edition", "the only edition of a book cannot be deleted")
+		default:
+			return apperrors.Internal(err)
+		}
+	}
+	return nil
+}
+
+func toEditionEntity(edition models.CreateOrUpdateEditionRequest) entities.Edition {
+	return entities.Edition{
+		Format:          edition.Format,
+		Publisher:       edition.Publisher,
+		Language:        edition.Language,
+		PageCount:       edition.PageCount,
+		PublicationYear: edition.PublicationYear,
+	}
+}
+
+func editionNotFound(bookID, id uuid.UUID) error {
+	return apperrors.NotFound("edition_not_found", fmt.Sprintf("edition with id = %s not found for book %s", id, bookID))
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/logger"
+	"gin_main/pkg/tabular"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// importBatchSize - сколько строк записывается в одной транзакции
+const importBatchSize = 500
+
+const (
+	importColumnTitle            = "title"
+	importColumnYear             = "year"
+	importColumnISBN             = "isbn"
+	importColumnAuthorSurname    = "authorSurname"
+	importColumnAuthorFirstName  = "authorFirstName"
+	importColumnAuthorSecondName = "authorSecondName"
+	importColumnAuthorBirth      = "authorDateOfBirth"
+)
+
+// importColumnAliases сопоставляет нормализованный заголовок колонки (нижний регистр, без пробелов и _) с полем
+var importColumnAliases = map[string]string{
+	"title": importColumnTitle, "название": importColumnTitle,
+	"year": importColumnYear, "dateofwriting": importColumnYear, "год": importColumnYear,
+	"isbn": importColumnISBN, "isbn13": importColumnISBN, "isbn10": importColumnISBN, "ean": importColumnISBN,
+	"authorsurname": importColumnAuthorSurname, "surname": importColumnAuthorSurname, "фамилия": importColumnAuthorSurname,
+	"authorfirstname": importColumnAuthorFirstName, "firstname": importColumnAuthorFirstName, "имя": importColumnAuthorFirstName,
+	"authorsecondname": importColumnAuthorSecondName, "secondname": importColumnAuthorSecondName, "отчество": importColumnAuthorSecondName,
+	"authordateofbirth": importColumnAuthorBirth, "dateofbirth": importColumnAuthorBirth, "датарождения": importColumnAuthorBirth,
+}
+
+// importFieldColumns - колонка файла для поля CreateOrUpdateBookRequest, чтобы ошибка проверки указывала на неё
+var importFieldColumns = map[string]string{
+	"Title":         importColumnTitle,
+	"DateOfWriting": importColumnYear,
+	"ISBN":          importColumnISBN,
+}
+
+var importRequiredColumns = []string{importColumnTitle, importColumnYear, importColumnAuthorSurname}
+
+// importDateLayouts - форматы дат в файлах; год без дня означает 1 января
+var importDateLayouts = []string{time.DateOnly, "02.01.2006", "2006"}
+
+type ImportServiceInterface interface {
+	Import(ctx context.Context, file io.Reader, format string, dryRun bool) (models.ImportReport, error)
+}
+
+type importService struct {
+	importRepo repositories.ImportRepositoryInterface
+}
+
+func NewImportService(importRepo repositories.ImportRepositoryInterface) ImportServiceInterface {
+	return &importService{importRepo: importRepo}
+}
+
+type importRow struct {
+	number int
+	book   entities.Book
+}
+
+func (s *importService) Import(ctx context.Context, file io.Reader, format string, dryRun bool) (models.ImportReport, error) {
+	ctx, span := tracer.Start(ctx, "ImportService.Import")
+	defer span.End()
+
+	reader, err := tabular.NewReader(file, format)
+	if err != nil {
+		return models.ImportReport{}, apperrors.Validation("unsupported_format", err.Error()).Wrap(err)
+	}
+	defer reader.Close()
+
+	header, err := reader.Next()
+	if err != nil {
+		return models.ImportReport{}, apperrors.Validation("missing_header", "file has no header row").Wrap(err)
+	}
+	columns, inError := importColumns(header)
+	if inError != nil {
+		return models.ImportReport{}, inError
+	}
+
+	report := models.ImportReport{DryRun: dryRun, Errors: []models.ImportRowError{}}
+	batch := make([]importRow, 0, importBatchSize)
+	// firstISBNRows - строка, где ISBN встретился впервые: второй такой книге база откажет, поэтому она отклоняется сразу
+	firstISBNRows := make(map[string]int)
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		number := reader.Line()
+		if err != nil {
+			report.Total++
+			report.Failed++
+			report.Errors = append(report.Errors, models.ImportRowError{Row: number, Message: err.Error()})
+			// повреждённый CSV дальше читать бессмысленно, остальные строки не учитываются
+			if format == tabular.FormatCSV && !isRecoverableCSVError(err) {
+				break
+			}
+			continue
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		report.Total++
+		book, rowErrors := parseImportRow(number, record, columns)
+		if len(rowErrors) == 0 && book.ISBN != nil {
+			if first, duplicated := firstISBNRows[*book.ISBN]; duplicated {
+				rowErrors = append(rowErrors, models.ImportRowError{Row: number, Column: importColumnISBN, Message: fmt.Sprintf("isbn %s duplicates row %d", *book.ISBN, first)})
+			} else {
+				firstISBNRows[*book.ISBN] = number
+			}
+		}
+		if len(rowErrors) > 0 {
+			report.Failed++
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+		batch = append(batch, importRow{number: number, book: book})
+		if len(batch) == importBatchSize {
+			s.importBatch(ctx, batch, dryRun, &report)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		s.importBatch(ctx, batch, dryRun, &report)
+	}
+	logger.FromContext(ctx).Info().
+		Bool("dryRun", dryRun).Int("total", report.Total).Int("imported", report.Imported).Int("failed", report.Failed).
+		Msg("Catalog import finished")
+	return report, nil
+}
+
+// importBatch отклоняет строки с ISBN, уже занятыми в каталоге, и записывает остальные одной транзакцией.
+// При dryRun проверка та же, но ничего не записывается, поэтому пробный прогон находит те же ошибки, что и настоящий
+func (s *importService) importBatch(ctx context.Context, batch []importRow, dryRun bool, report *models.ImportReport) {
+	rows, err := s.withoutTakenISBNs(ctx, batch, report)
+	if err != nil {
+		failBatch(ctx, batch, err, report)
+		return
+	}
+	report.Valid += len(rows)
+	if dryRun || len(rows) == 0 {
+		return
+	}
+	books := make([]entities.Book, 0, len(rows))
+	for _, row := range rows {
+		books = append(books, row.book)
+	}
+	authorsCreated, err := s.importRepo.ImportBooks(ctx, books)
+	if err != nil {
+		// сюда попадают только ошибки базы и гонки с параллельной записью: ISBN проверены выше
+		failBatch(ctx, rows, err, report)
+		return
+	}
+	report.Imported += len(rows)
+	report.AuthorsCreated += authorsCreated
+}
+
+// withoutTakenISBNs записывает в отчёт строки, чей ISBN уже есть в каталоге, и возвращает остальные
+func (s *importService) withoutTakenISBNs(ctx context.Context, batch []importRow, report *models.ImportReport) ([]importRow, error) {
+	isbns := make([]string, 0, len(batch))
+	for _, row := range batch {
+		if row.book.ISBN != nil {
+			isbns = append(isbns, *row.book.ISBN)
+		}
+	}
+	existing, err := s.importRepo.ExistingISBNs(ctx, isbns)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return batch, nil
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, isbn := range existing {
+		taken[isbn] = true
+	}
+	rows := make([]importRow, 0, len(batch))
+	for _, row := range batch {
+		if row.book.ISBN != nil && taken[*row.book.ISBN] {
+			report.Failed++
+			report.Errors = append(report.Errors, models.ImportRowError{Row: row.number, Column: importColumnISBN, Message: fmt.Sprintf("book with isbn %s already exists", *row.book.ISBN)})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// failBatch отмечает в отчёте все строки пачки как незаписанные
+func failBatch(ctx context.Context, batch []importRow, err error, report *models.ImportReport) {
+	// причина не попадает в отчёт для клиента, поэтому пишется в лог вместе с request_id
+	logger.FromContext(ctx).Error().Err(err).
+		Int("fromRow", batch[0].number).Int("toRow", batch[len(batch)-1].number).
+		Msg("Import batch failed")
+	report.Failed += len(batch)
+	message := fmt.Sprintf("rows %d-%d were not imported: batch failed", batch[0].number, batch[len(batch)-1].number)
+	for _, row := range batch {
+		report.Errors = append(report.Errors, models.ImportRowError{Row: row.number, Message: message})
+	}
+}
+
+func importColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, name := range header {
+		normalized := strings.NewReplacer(" ", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+		if field, ok := importColumnAliases[normalized]; ok {
+			if _, duplicated := columns[field]; duplicated {
+				return nil, apperrors.Validation("duplicated_column", fmt.Sprintf("column %s appears twice", field))
+			}
+			columns[field] = i
+		}
+	}
+	var missing []string
+	for _, field := range importRequiredColumns {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperrors.Validation("missing_columns", "missing required columns: "+strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// parseImportRow переводит строку файла в CreateOrUpdateBookRequest и проверяет её теми же правилами, что и API
+func parseImportRow(number int, record []string, columns map[string]int) (entities.Book, []models.ImportRowError) {
+	value := func(field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var rowErrors []models.ImportRowError
+	fail := func(column, message string) {
+		rowErrors = append(rowErrors, models.ImportRowError{Row: number, Column: column, Message: message})
+	}
+
+	request := models.CreateOrUpdateBookRequest{
+		Title: value(importColumnTitle),
+		ISBN:  value(importColumnISBN),
+		Author: models.Author{
+			Surname:    value(importColumnAuthorSurname),
+			FirstName:  value(importColumnAuthorFirstName),
+			SecondName: value(importColumnAuthorSecondName),
+		},
+	}
+	if year := value(importColumnYear); year != "" {
+		var err error
+		if request.DateOfWriting, err = parseImportDate(year); err != nil {
+			fail(importColumnYear, err.Error())
+		}
+	}
+	if birth := value(importColumnAuthorBirth); birth != "" {
+		var err error
+		if request.Author.DateOfBirth, err = parseImportDate(birth); err != nil {
+			fail(importColumnAuthorBirth, err.Error())
+		}
+	}
+	if request.Author.Surname == "" {
+		fail(importColumnAuthorSurname, "author surname is required")
+	}
+	if err := binding.Validator.ValidateStruct(&request); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			fail("", err.Error())
+		}
+		for _, fieldError := range validationErrors {
+			column := importFieldColumns[fieldError.StructField()]
+			if column == importColumnYear && request.DateOfWriting.IsZero() && value(importColumnYear) != "" {
+				continue // ошибка разбора даты уже записана
+			}
+			fail(column, fmt.Sprintf("value does not satisfy rule %q", fieldError.ActualTag()))
+		}
+	}
+	if len(rowErrors) > 0 {
+		return entities.Book{}, rowErrors
+	}
+	code, _ := normalizeISBN(request.ISBN) // контрольная сумма уже проверена правилом isbn
+	return entities.Book{
+		Title:         request.Title,
+		ISBN:          code,
+		DateOfWriting: request.DateOfWriting,
+		Contributors: []entities.BookContributor{{
+			Role: entities.ContributorAuthor,
+			Author: entities.Author{
+				Surname:     request.Author.Surname,
+				FirstName:   request.Author.FirstName,
+				SecondName:  request.Author.SecondName,
+				DateOfBirth: request.Author.DateOfBirth,
+			},
+		}},
+	}, nil
+}
+
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse date %q, expected YYYY-MM-DD, DD.MM.YYYY or YYYY", value)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isRecoverableCSVError отличает ошибку в одной строке (например, лишняя кавычка) от обрыва чтения
+func isRecoverableCSVError(err error) bool {
+	var parseErr *csv.ParseError
+	return errors.As(err, &parseErr)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type fakeImportRepository struct {
+	batches [][]entities.Book
+	isbns   []string // ISBN книг, уже записанных в каталог
+	err     error
+}
+
+func (f *fakeImportRepository) ImportBooks(ctx context.Context, books []entities.Book) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.batches = append(f.batches, append([]entities.Book(nil), books...))
+	return 1, nil
+}
+
+func (f *fakeImportRepository) ExistingISBNs(ctx context.Context, isbns []string) ([]string, error) {
+	existing := []string{}
+	for _, isbn := range isbns {
+		if slices.Contains(f.isbns, isbn) {
+			existing = append(existing, isbn)
+		}
+	}
+	return existing, nil
+}
+
+func TestImportDryRunReportsRowErrors(t *testing.T) {
+	file := "title,year,author_surname,author first name,extra\n" +
+		"War and Peace,1869,Tolstoy,Lev,x\n" +
+		",1877,Tolstoy,Lev,\n" +
+		"Dead Souls,someday,Gogol,Nikolai,\n" +
+		"\n" +
+		"The Nose,1836,,,\n"
+	repo := &fakeImportRepository{}
+	report, inError := NewImportService(repo).Import(context.Background(), strings.NewReader(file), "csv", true)
+	if inError != nil {
+		t.Fatalf("unexpected error %+v", inError)
+	}
+	if len(repo.batches) != 0 {
+		t.Fatalf("dry run wrote %d batches", len(repo.batches))
+	}
+	if report.Total != 4 || report.Valid != 1 || report.Failed != 3 || report.Imported != 0 || !report.DryRun {
+		t.Errorf("report = %+v", report)
+	}
+	wantRows := map[int]string{3: "title", 4: "year", 6: "authorSurname"}
+	if len(report.Errors) != len(wantRows) {
+		t.Fatalf("errors = %+v", report.Errors)
+	}
+	for _, rowError := range report.Errors {
+		if wantRows[rowError.Row] != rowError.Column {
+			t.Errorf("row %d: column = %q, want %q (%s)", rowError.Row, rowError.Column, wantRows[rowError.Row], rowError.Message)
+		}
+	}
+}
+
+func TestImportWritesValidRows(t *testing.T) {
+	file := "Название,Год,Фамилия,Имя,Дата рождения\n" +
+		"Война и мир,1869,Толстой,Лев,09.09.1828\n" +
+		"Анна Каренина,1877-01-01,Толстой,Лев,1828-09-09\n" +
+		"Без автора,1900,,,\n"
+	repo := &fakeImportRepository{}
+	report, inError := NewImportService(repo).Import(context.Background(), strings.NewReader(file), "csv", false)
+	if inError != nil {
+		t.Fatalf("unexpected error %+v", inError)
+	}
+	if report.Imported != 2 || report.Failed != 1 || report.AuthorsCreated != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(repo.batches) != 1 || len(repo.batches[0]) != 2 {
+		t.Fatalf("batches = %+v", repo.batches)
+	}
+	if got := repo.batches[0][0].Contributors[0].Author.DateOfBirth.Format("2006-01-02"); got != "1828-09-09" {
+		t.Errorf("date of birth = %s", got)
+	}
+
+	failing := &fakeImportRepository{err: errors.New("connection reset")}
+	report, _ = NewImportService(failing).Import(context.Background(), strings.NewReader(file), "csv", false)
+	if report.Imported != 0 || report.Failed != 3 {
+		t.Errorf("failed batch: report = %+v", report)
+	}
+}
+
+func TestImportNormalizesISBN(t *testing.T) {
+	file := "title,year,surname,ISBN\n" +
+		"War and Peace,1869,Tolstoy,5-17-090630-7\n" +
+		"Anna Karenina,1877,Tolstoy,978-0-306-40615-8\n" +
+		"Resurrection,1899,Tolstoy,\n"
+	repo := &fakeImportRepository{}
+	report, _ := NewImportService(repo).Import(context.Background(), strings.NewReader(file), "csv", false)
+	if report.Imported != 2 || len(report.Errors) != 1 || report.Errors[0].Row != 3 || report.Errors[0].Column != "isbn" {
+		t.Fatalf("report = %+v", report)
+	}
+	books := repo.batches[0]
+	if books[0].ISBN == nil || *books[0].ISBN != "9785170906307" || books[1].ISBN != nil {
+		t.Errorf("isbn = %v, %v", books[0].ISBN, books[1].ISBN)
+	}
+}
+
+func TestImportRejectsMissingColumns(t *testing.T) {
+	_, inError := NewImportService(&fakeImportRepository{}).Import(context.Background(), strings.NewReader("title,author\nx,y\n"), "csv", true)
+	if !apperrors.Is(inError, apperrors.KindValidation) || !strings.Contains(inError.Error(), "year") {
+		t.Errorf("error = %+v", inError)
+	}
+}
+
+func TestImportRejectsDuplicateISBN(t *testing.T) {
+	// вторая строка - та же книга, что и первая, в виде ISBN-10; третья уже есть в каталоге
+	file := "title,year,surname,isbn\n" +
+		"War and Peace,1869,Tolstoy,978-5-17-090630-7\n" +
+		"War and Peace,1869,Tolstoy,5-17-090630-7\n" +
+		"Anna Karenina,1877,Tolstoy,978-0-306-40615-7\n" +
+		"Resurrection,1899,Tolstoy,\n"
+	for _, dryRun := range []bool{true, false} {
+		repo := &fakeImportRepository{isbns: []string{"9780306406157"}}
+		report, inError := NewImportService(repo).Import(context.Background(), strings.NewReader(file), "csv", dryRun)
+		if inError != nil {
+			t.Fatalf("dryRun=%v: unexpected error %+v", dryRun, inError)
+		}
+		if report.Total != 4 || report.Valid != 2 || report.Failed != 2 {
+			t.Errorf("dryRun=%v: report = %+v", dryRun, report)
+		}
+		wantRows := map[int]string{3: "duplicates row 2", 4: "already exists"}
+		if len(report.Errors) != len(wantRows) {
+			t.Fatalf("dryRun=%v: errors = %+v", dryRun, report.Errors)
+		}
+		for _, rowError := range report.Errors {
+			if rowError.Column != "isbn" || !strings.Contains(rowError.Message, wantRows[rowError.Row]) {
+				t.Errorf("dryRun=%v: row error = %+v", dryRun, rowError)
+			}
+		}
+		if dryRun {
+			if len(repo.batches) != 0 {
+				t.Errorf("dry run wrote %d batches", len(repo.batches))
+			}
+			continue
+		}
+		if report.Imported != 2 || len(repo.batches) != 1 || len(repo.batches[0]) != 2 {
+			t.Errorf("imported %d, batches = %+v", report.Imported, repo.batches)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+// orderTransitions перечисляет допустимые переходы жизненного цикла заказа
+var orderTransitions = map[string][]string{
+	models.OrderDraft:   {models.OrderPlaced, models.OrderCancelled},
+	models.OrderPlaced:  {models.OrderPicking, models.OrderCancelled},
+	models.OrderPicking: {models.OrderPacked, models.OrderCancelled},
+	models.OrderPacked:  {models.OrderShipped, models.OrderCancelled},
+}
+
+type OrderServiceInterface interface {
+	Create(ctx context.Context, order models.CreateOrderRequest, actor models.Actor) (models.Order, error)
+	FindById(ctx context.Context, id uuid.UUID) (models.Order, error)
+	GetAll(ctx context.Context, status string) ([]models.Order, error)
+	ChangeStatus(ctx context.Context, id uuid.UUID, status string, actor models.Actor) (models.Order, error)
+}
+
+type orderService struct {
+	orderRepo      repositories.OrderRepositoryInterface
+	reservationTTL time.Duration
+}
+
+func NewOrderService(orderRepo repositories.OrderRepositoryInterface, reservationTTL time.Duration) OrderServiceInterface {
+	return &orderService{orderRepo: orderRepo, reservationTTL: reservationTTL}
+}
+
+func (o *orderService) Create(ctx context.Context, order models.CreateOrderRequest, actor models.Actor) (models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.Create")
+	defer span.End()
+
+	orderEntity := entities.Order{
+		CustomerName: order.CustomerName,
+		Comment:      order.Comment,
+	}
+	if actor.UserID != uuid.Nil {
+		orderEntity.CreatedBy = &actor.UserID
+	}
+	for _, line := range order.Lines {
+		if slices.ContainsFunc(orderEntity.Lines, func(l entities.OrderLine) bool { return l.BookID == line.BookID }) {
+			return models.Order{}, apperrors.Validation("duplicated_order_line", fmt.Sprintf("book %s appears in several order lines", line.BookID))
+		}
+		orderEntity.Lines = append(orderEntity.Lines, entities.OrderLine{BookID: line.BookID, Quantity: line.Quantity})
+	}
+	newOrder, err := o.orderRepo.Create(ctx, orderEntity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Order{}, apperrors.NotFound("book_not_found", "order references a book that does not exist")
+		}
+		return models.Order{}, apperrors.Internal(err)
+	}
+	return toOrderModel(newOrder)
+}
+
+func (o *orderService) FindById(ctx context.Context, id uuid.UUID) (models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.FindById")
+	defer span.End()
+
+	order, err := o.orderRepo.FindById(ctx, id)
+	if err != nil {
+		return models.Order{}, orderError(id, err)
+	}
+	return toOrderModel(order)
+}
+
+func (o *orderService) GetAll(ctx context.Context, status string) ([]models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.GetAll")
+	defer span.End()
+
+	orders, err := o.orderRepo.GetAll(ctx, status)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	ordersResult := make([]models.Order, 0, len(orders))
+	for _, order := range orders {
+		orderResult, inError := toOrderModel(order)
+		if inError != nil {
+			return nil, inError
+		}
+		ordersResult = append(ordersResult, orderResult)
+	}
+	return ordersResult, nil
+}
+
+func (o *orderService) ChangeStatus(ctx context.Context, id uuid.UUID, status string, actor models.Actor) (models.Order, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.ChangeStatus")
+	defer span.End()
+
+	order, err := o.orderRepo.FindById(ctx, id)
+	if err != nil {
+		return models.Order{}, orderError(id, err)
+	}
+	if allowed := orderTransitions[order.Status]; !slices.Contains(allowed, status) {
+		return models.Order{}, apperrors.Conflict("invalid_status_transition", fmt.Sprintf("order cannot move from %s to %s", order.Status, status)).
+			WithDetails(models.OrderTransitionError{Status: order.Status, Target: status, Allowed: allowedOrEmpty(allowed)})
+	}
+	order, err = o.orderRepo.ChangeStatus(ctx, id, order.Status, status, time.Now().Add(o.reservationTTL), repositories.MovementInfo{
+		ActorID:       actor.UserID,
+		CorrelationID: actor.CorrelationID,
+	})
+	if err != nil {
+		return models.Order{}, orderError(id, err)
+	}
+	return toOrderModel(order)
+}
+
+func toOrderModel(order entities.Order) (models.Order, error) {
+	var orderResult models.Order
+	if err := copier.Copy(&orderResult, &order); err != nil {
+		return models.Order{}, apperrors.Internal(err)
+	}
+	if orderResult.Lines == nil {
+		orderResult.Lines = []models.OrderLine{}
+	}
+	return orderResult, nil
+}
+
+func allowedOrEmpty(allowed []string) []string {
+	if allowed == nil {
+		return []string{}
+	}
+	return allowed
+}
+
+func orderError(id uuid.UUID, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperrors.NotFound("order_not_found", fmt.Sprintf("order with id = %s not found", id.String()))
+	case errors.Is(err, repositories.ErrOrderStatusChanged):
+		return apperrors.Conflict("order_status_changed", "order status was changed by another request, reload and retry")
+	case errors.Is(err, repositories.ErrInsufficientStock), errors.Is(err, repositories.ErrStockReserved):
+		return apperrors.InsufficientStock("insufficient_stock", fmt.Sprintf("not enough available stock: %s", err.Error())).Wrap(err)
+	default:
+		return apperrors.Internal(err)
+	}
+}
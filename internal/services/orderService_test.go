@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// orderStatusChange - вызов ChangeStatus репозитория; побочные эффекты перехода выполняет репозиторий
+type orderStatusChange struct {
+	from, to             string
+	reservationExpiresAt time.Time
+	info                 repositories.MovementInfo
+}
+
+type fakeOrderRepository struct {
+	orders  map[uuid.UUID]entities.Order
+	changes []orderStatusChange
+	err     error
+}
+
+func (f *fakeOrderRepository) Create(ctx context.Context, order entities.Order) (entities.Order, error) {
+	order.ID = uuid.New()
+	order.Status = entities.OrderDraft
+	f.orders[order.ID] = order
+	return order, nil
+}
+
+func (f *fakeOrderRepository) FindById(ctx context.Context, id uuid.UUID) (entities.Order, error) {
+	order, ok := f.orders[id]
+	if !ok {
+		return entities.Order{}, gorm.ErrRecordNotFound
+	}
+	return order, nil
+}
+
+func (f *fakeOrderRepository) GetAll(ctx context.Context, status string) ([]entities.Order, error) {
+	return nil, nil
+}
+
+func (f *fakeOrderRepository) ChangeStatus(ctx context.Context, id uuid.UUID, from, to string, reservationExpiresAt time.Time, info repositories.MovementInfo) (entities.Order, error) {
+	f.changes = append(f.changes, orderStatusChange{from: from, to: to, reservationExpiresAt: reservationExpiresAt, info: info})
+	if f.err != nil {
+		return entities.Order{}, f.err
+	}
+	order := f.orders[id]
+	order.Status = to
+	f.orders[id] = order
+	return order, nil
+}
+
+func TestOrderStatusTransitions(t *testing.T) {
+	statuses := []string{models.OrderDraft, models.OrderPlaced, models.OrderPicking, models.OrderPacked, models.OrderShipped, models.OrderCancelled}
+	allowed := map[string][]string{
+		models.OrderDraft:   {models.OrderPlaced, models.OrderCancelled},
+		models.OrderPlaced:  {models.OrderPicking, models.OrderCancelled},
+		models.OrderPicking: {models.OrderPacked, models.OrderCancelled},
+		models.OrderPacked:  {models.OrderShipped, models.OrderCancelled},
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			t.Run(from+"->"+to, func(t *testing.T) {
+				id := uuid.New()
+				repo := &fakeOrderRepository{orders: map[uuid.UUID]entities.Order{id: {ID: id, Status: from}}}
+				order, inError := NewOrderService(repo, time.Hour).ChangeStatus(context.Background(), id, to, models.Actor{})
+
+				if slices.Contains(allowed[from], to) {
+					if inError != nil || order.Status != to || len(repo.changes) != 1 || repo.changes[0].from != from {
+						t.Fatalf("allowed transition: order = %+v, error = %v, changes = %+v", order, inError, repo.changes)
+					}
+					return
+				}
+				if len(repo.changes) != 0 {
+					t.Errorf("disallowed transition reached the repository: %+v", repo.changes)
+				}
+				problem := apperrors.From(inError)
+				if problem.Code != "invalid_status_transition" || apperrors.Status(problem.Kind) != http.StatusConflict {
+					t.Fatalf("error = %v, want 409 invalid_status_transition", inError)
+				}
+				details, ok := problem.Details.(models.OrderTransitionError)
+				wantAllowed := allowed[from]
+				if wantAllowed == nil {
+					wantAllowed = []string{}
+				}
+				if !ok || details.Status != from || details.Target != to || !slices.Equal(details.Allowed, wantAllowed) {
+					t.Errorf("details = %+v, want allowed %v", problem.Details, wantAllowed)
+				}
+			})
+		}
+	}
+}
+
+func TestOrderStatusSideEffects(t *testing.T) {
+	id := uuid.New()
+	repo := &fakeOrderRepository{orders: map[uuid.UUID]entities.Order{id: {ID: id, Status: models.OrderPlaced}}}
+	service := NewOrderService(repo, 72*time.Hour)
+	actor := models.Actor{UserID: uuid.New(), CorrelationID: "request-1"}
+
+	start := time.Now()
+	if _, inError := service.ChangeStatus(context.Background(), id, models.OrderPicking, actor); inError != nil {
+		t.Fatalf("picking: %v", inError)
+	}
+	// резерв под строки создаёт репозиторий, сервис задаёт его срок
+	if expiresAt := repo.changes[0].reservationExpiresAt; expiresAt.Before(start.Add(72*time.Hour)) || expiresAt.After(time.Now().Add(72*time.Hour)) {
+		t.Errorf("picking: reservation expires at %v, want now + reservation ttl", expiresAt)
+	}
+
+	if _, inError := service.ChangeStatus(context.Background(), id, models.OrderPacked, actor); inError != nil {
+		t.Fatalf("packed: %v", inError)
+	}
+	if _, inError := service.ChangeStatus(context.Background(), id, models.OrderShipped, actor); inError != nil {
+		t.Fatalf("shipped: %v", inError)
+	}
+	// списание идёт через ChangeQuantity репозитория и должно попасть в журнал движений от имени вызывающего
+	if info := repo.changes[2].info; info.ActorID != actor.UserID || info.CorrelationID != actor.CorrelationID {
+		t.Errorf("shipped: movement info = %+v, want the actor", info)
+	}
+}
+
+func TestOrderStatusRepositoryErrors(t *testing.T) {
+	tests := []struct {
+		err      error
+		wantCode string
+		wantKind apperrors.Kind
+	}{
+		{fmt.Errorf("book %s: %w", uuid.New(), repositories.ErrInsufficientStock), "insufficient_stock", apperrors.KindInsufficientStock},
+		{repositories.ErrStockReserved, "insufficient_stock", apperrors.KindInsufficientStock},
+		{repositories.ErrOrderStatusChanged, "order_status_changed", apperrors.KindConflict},
+	}
+	for _, tt := range tests {
+		id := uuid.New()
+		repo := &fakeOrderRepository{orders: map[uuid.UUID]entities.Order{id: {ID: id, Status: models.OrderPlaced}}, err: tt.err}
+		_, inError := NewOrderService(repo, time.Hour).ChangeStatus(context.Background(), id, models.OrderPicking, models.Actor{})
+		if problem := apperrors.From(inError); problem.Code != tt.wantCode || problem.Kind != tt.wantKind {
+			t.Errorf("%v: error = %v, want %s", tt.err, inError, tt.wantCode)
+		}
+	}
+
+	_, inError := NewOrderService(&fakeOrderRepository{orders: map[uuid.UUID]entities.Order{}}, time.Hour).
+		ChangeStatus(context.Background(), uuid.New(), models.OrderPlaced, models.Actor{})
+	if !apperrors.Is(inError, apperrors.KindNotFound) {
+		t.Errorf("missing order: error = %v, want not found", inError)
+	}
+}
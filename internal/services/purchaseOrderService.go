@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+type PurchaseOrderServiceInterface interface {
+	CreateSupplier(ctx context.Context, supplier models.CreateSupplierRequest) (models.CreateSupplierResponse, error)
+	GetAllSuppliers(ctx context.Context) ([]models.Supplier, error)
+	FindSupplierById(ctx context.Context, id uuid.UUID) (models.Supplier, error)
+	Create(ctx context.Context, order models.CreatePurchaseOrderRequest, actor models.Actor) (models.PurchaseOrder, error)
+	FindById(ctx context.Context, id uuid.UUID) (models.PurchaseOrder, error)
+	GetAll(ctx context.Context, status string, supplierID uuid.UUID) ([]models.PurchaseOrder, error)
+	GetReceipts(ctx context.Context, id uuid.UUID) ([]models.PurchaseReceipt, error)
+	Receive(ctx context.Context, id uuid.UUID, receipt models.ReceivePurchaseOrderRequest, actor models.Actor) (models.ReceivePurchaseOrderResponse, error)
+	Close(ctx context.Context, id uuid.UUID) (models.PurchaseOrder, error)
+	PendingReport(ctx context.Context, asOf time.Time) (models.PendingPurchaseOrdersReport, error)
+}
+
+type purchaseOrderService struct {
+	purchaseOrderRepo repositories.PurchaseOrderRepositoryInterface
+}
+
+func NewPurchaseOrderService(purchaseOrderRepo repositories.PurchaseOrderRepositoryInterface) PurchaseOrderServiceInterface {
+	return &purchaseOrderService{purchaseOrderRepo: purchaseOrderRepo}
+}
+
+func (p *purchaseOrderService) CreateSupplier(ctx context.Context, supplier models.CreateSupplierRequest) (models.CreateSupplierResponse, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.CreateSupplier")
+	defer span.End()
+
+	newSupplier, err := p.purchaseOrderRepo.CreateSupplier(ctx, entities.Supplier{
+		Name:  supplier.Name,
+		Email: supplier.Email,
+		Phone: supplier.Phone,
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrSupplierExists) {
+			return models.CreateSupplierResponse{}, apperrors.Conflict("supplier_exists", fmt.Sprintf("supplier with name = %s already exists", supplier.Name))
+		}
+		return models.CreateSupplierResponse{}, apperrors.Internal(err)
+	}
+	return models.CreateSupplierResponse{ID: newSupplier.ID}, nil
+}
+
+func (p *purchaseOrderService) GetAllSuppliers(ctx context.Context) ([]models.Supplier, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.GetAllSuppliers")
+	defer span.End()
+
+	suppliersEntities, err := p.purchaseOrderRepo.GetAllSuppliers(ctx)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	suppliers := []models.Supplier{}
+	if err = copier.Copy(&suppliers, &suppliersEntities); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return suppliers, nil
+}
+
+func (p *purchaseOrderService) FindSupplierById(ctx context.Context, id uuid.UUID) (models.Supplier, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.FindSupplierById")
+	defer span.End()
+
+	supplierEntity, err := p.purchaseOrderRepo.FindSupplierById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Supplier{}, supplierNotFound(id)
+		}
+		return models.Supplier{}, apperrors.Internal(err)
+	}
+	var supplier models.Supplier
+	if err = copier.Copy(&supplier, &supplierEntity); err != nil {
+		return models.Supplier{}, apperrors.Internal(err)
+	}
+	return supplier, nil
+}
+
+func (p *purchaseOrderService) Create(ctx context.Context, order models.CreatePurchaseOrderRequest, actor models.Actor) (models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.Create")
+	defer span.End()
+
+	expectedAt, err := time.Parse(time.DateOnly, order.ExpectedAt)
+	if err != nil {
+		return models.PurchaseOrder{}, apperrors.Validation("invalid_date", "expectedAt must be a date in YYYY-MM-DD format").
+			WithField("expectedAt", "must be a date in YYYY-MM-DD format")
+	}
+	if _, inError := p.FindSupplierById(ctx, order.SupplierID); inError != nil {
+		return models.PurchaseOrder{}, inError
+	}
+	orderEntity := entities.PurchaseOrder{
+		SupplierID: order.SupplierID,
+		ExpectedAt: expectedAt,
+		Comment:    order.Comment,
+	}
+	if actor.UserID != uuid.Nil {
+		orderEntity.CreatedBy = &actor.UserID
+	}
+	seen := make(map[uuid.UUID]bool, len(order.Lines))
+	for _, line := range order.Lines {
+		if seen[line.BookID] {
+			return models.PurchaseOrder{}, duplicatedBookLine(line.BookID)
+		}
+		seen[line.BookID] = true
+		orderEntity.Lines = append(orderEntity.Lines, entities.PurchaseOrderLine{BookID: line.BookID, ExpectedQuantity: line.Quantity})
+	}
+	newOrder, err := p.purchaseOrderRepo.Create(ctx, orderEntity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.PurchaseOrder{}, apperrors.NotFound("book_not_found", "purchase order references a book that does not exist")
+		}
+		return models.PurchaseOrder{}, apperrors.Internal(err)
+	}
+	return toPurchaseOrderModel(newOrder)
+}
+
+func (p *purchaseOrderService) FindById(ctx context.Context, id uuid.UUID) (models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.FindById")
+	defer span.End()
+
+	order, err := p.purchaseOrderRepo.FindById(ctx, id)
+	if err != nil {
+		return models.PurchaseOrder{}, purchaseOrderError(id, err)
+	}
+	return toPurchaseOrderModel(order)
+}
+
+func (p *purchaseOrderService) GetAll(ctx context.Context, status string, supplierID uuid.UUID) ([]models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.GetAll")
+	defer span.End()
+
+	orders, err := p.purchaseOrderRepo.GetAll(ctx, status, supplierID)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	ordersResult := make([]models.PurchaseOrder, 0, len(orders))
+	for _, order := range orders {
+		orderResult, inError := toPurchaseOrderModel(order)
+		if inError != nil {
+			return nil, inError
+		}
+		ordersResult = append(ordersResult, orderResult)
+	}
+	return ordersResult, nil
+}
+
+func (p *purchaseOrderService) GetReceipts(ctx context.Context, id uuid.UUID) ([]models.PurchaseReceipt, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.GetReceipts")
+	defer span.End()
+
+	if _, err := p.purchaseOrderRepo.FindById(ctx, id); err != nil {
+		return nil, purchaseOrderError(id, err)
+	}
+	receiptsEntities, err := p.purchaseOrderRepo.GetReceipts(ctx, id)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	receipts := []models.PurchaseReceipt{}
+	if err = copier.Copy(&receipts, &receiptsEntities); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return receipts, nil
+}
+
+func (p *purchaseOrderService) Receive(ctx context.Context, id uuid.UUID, receipt models.ReceivePurchaseOrderRequest, actor models.Actor) (models.ReceivePurchaseOrderResponse, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.Receive")
+	defer span.End()
+
+	items := make([]repositories.ReceiptItem, 0, len(receipt.Lines))
+	seen := make(map[uuid.UUID]bool, len(receipt.Lines))
+	for _, line := range receipt.Lines {
+		if seen[line.BookID] {
+			return models.ReceivePurchaseOrderResponse{}, duplicatedBookLine(line.BookID)
+		}
+		seen[line.BookID] = true
+		if line.Received+line.Damaged == 0 {
+			return models.ReceivePurchaseOrderResponse{}, apperrors.Validation("empty_receipt_line", fmt.Sprintf("receipt line for book %s has no received or damaged units", line.BookID))
+		}
+		items = append(items, repositories.ReceiptItem{BookID: line.BookID, EditionID: line.EditionID, Received: line.Received, Damaged: line.Damaged})
+	}
+	order, receiptEntity, err := p.purchaseOrderRepo.Receive(ctx, id, receipt.LocationID, items, repositories.MovementInfo{
+		ActorID:       actor.UserID,
+		CorrelationID: actor.CorrelationID,
+		Comment:       receipt.Comment,
+	})
+	if err != nil {
+		return models.ReceivePurchaseOrderResponse{}, purchaseOrderError(id, err)
+	}
+	orderResult, inError := toPurchaseOrderModel(order)
+	if inError != nil {
+		return models.ReceivePurchaseOrderResponse{}, inError
+	}
+	var receiptResult models.PurchaseReceipt
+	if err = copier.Copy(&receiptResult, &receiptEntity); err != nil {
+		return models.ReceivePurchaseOrderResponse{}, apperrors.Internal(err)
+	}
+	return models.ReceivePurchaseOrderResponse{PurchaseOrder: orderResult, Receipt: receiptResult}, nil
+}
+
+func (p *purchaseOrderService) Close(ctx context.Context, id uuid.UUID) (models.PurchaseOrder, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.Close")
+	defer span.End()
+
+	order, err := p.purchaseOrderRepo.Close(ctx, id)
+	if err != nil {
+		return models.PurchaseOrder{}, purchaseOrderError(id, err)
+	}
+	return toPurchaseOrderModel(order)
+}
+
+func (p *purchaseOrderService) PendingReport(ctx context.Context, asOf time.Time) (models.PendingPurchaseOrdersReport, error) {
+	ctx, span := tracer.Start(ctx, "PurchaseOrderService.PendingReport")
+	defer span.End()
+
+	orders, err := p.purchaseOrderRepo.GetPending(ctx)
+	if err != nil {
+		return models.PendingPurchaseOrdersReport{}, apperrors.Internal(err)
+	}
+	// просрочен заказ, ожидаемая дата которого уже прошла к началу дня asOf
+	today := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	report := models.PendingPurchaseOrdersReport{AsOf: today, Items: make([]models.PendingPurchaseOrder, 0, len(orders))}
+	for _, order := range orders {
+		item := models.PendingPurchaseOrder{
+			ID:           order.ID,
+			SupplierID:   order.SupplierID,
+			SupplierName: order.Supplier.Name,
+			Status:       order.Status,
+			ExpectedAt:   order.ExpectedAt,
+		}
+		for _, line := range order.Lines {
+			item.Outstanding += line.Outstanding()
+		}
+		expected := time.Date(order.ExpectedAt.Year(), order.ExpectedAt.Month(), order.ExpectedAt.Day(), 0, 0, 0, 0, time.UTC)
+		if expected.Before(today) {
+			item.Overdue = true
+			item.DaysOverdue = int(today.Sub(expected).Hours() / 24)
+			report.Overdue++
+		}
+		report.Open++
+		report.Items = append(report.Items, item)
+	}
+	return report, nil
+}
+
+func toPurchaseOrderModel(order entities.PurchaseOrder) (models.PurchaseOrder, error) {
+	var orderResult models.PurchaseOrder
+	if err := copier.Copy(&orderResult, &order); err != nil {
+		return models.PurchaseOrder{}, apperrors.Internal(err)
+	}
+	orderResult.Lines = make([]models.PurchaseOrderLine, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		orderResult.Lines = append(orderResult.Lines, models.PurchaseOrderLine{
+			ID:               line.ID,
+			BookID:           line.BookID,
+			ExpectedQuantity: line.ExpectedQuantity,
+			ReceivedQuantity: line.ReceivedQuantity,
+			DamagedQuantity:  line.DamagedQuantity,
+			Outstanding:      line.Outstanding(),
+			OverReceived:     max(line.ReceivedQuantity+line.DamagedQuantity-line.ExpectedQuantity, 0),
+		})
+	}
+	return orderResult, nil
+}
+
+func supplierNotFound(id uuid.UUID) error {
+	return apperrors.NotFound("supplier_not_found", fmt.Sprintf("supplier with id = %s not found", id.String()))
+}
+
+func duplicatedBookLine(bookID uuid.UUID) error {
+	return apperrors.Validation("duplicated_purchase_order_line", fmt.Sprintf("book %s appears in several lines", bookID))
+}
+
+func purchaseOrderError(id uuid.UUID, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperrors.NotFound("purchase_order_not_found", fmt.Sprintf("purchase order with id = %s not found", id.String()))
+	case errors.Is(err, repositories.ErrPurchaseOrderNotOpen):
+		return apperrors.Conflict("purchase_order_not_open", "purchase order is already received or closed")
+	case errors.Is(err, repositories.ErrBookNotOnPurchaseOrder),
+		errors.Is(err, repositories.ErrUnknownEdition),
+		errors.Is(err, repositories.ErrEditionRequired):
+		return apperrors.Validation("invalid_receipt_line", err.Error()).Wrap(err)
+	case errors.Is(err, repositories.ErrUnknownLocation):
+		return apperrors.NotFound("location_not_found", "location not found")
+	default:
+		return apperrors.Internal(err)
+	}
+}
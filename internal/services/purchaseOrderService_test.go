@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakePurchaseOrderRepository запоминает переданную приёмку и возвращает заранее заданный результат:
+// сложение количеств и смену статуса выполняет репозиторий, сервис за них не отвечает
+type fakePurchaseOrderRepository struct {
+	order  entities.PurchaseOrder
+	err    error
+	calls  int
+	items  []repositories.ReceiptItem
+	info   repositories.MovementInfo
+	target uuid.UUID // ячейка приёмки
+}
+
+func (f *fakePurchaseOrderRepository) CreateSupplier(ctx context.Context, supplier entities.Supplier) (entities.Supplier, error) {
+	return supplier, nil
+}
+
+func (f *fakePurchaseOrderRepository) GetAllSuppliers(ctx context.Context) ([]entities.Supplier, error) {
+	return nil, nil
+}
+
+func (f *fakePurchaseOrderRepository) FindSupplierById(ctx context.Context, id uuid.UUID) (entities.Supplier, error) {
+	return entities.Supplier{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakePurchaseOrderRepository) Create(ctx context.Context, order entities.PurchaseOrder) (entities.PurchaseOrder, error) {
+	return order, nil
+}
+
+func (f *fakePurchaseOrderRepository) FindById(ctx context.Context, id uuid.UUID) (entities.PurchaseOrder, error) {
+	return f.order, f.err
+}
+
+func (f *fakePurchaseOrderRepository) GetAll(ctx context.Context, status string, supplierID uuid.UUID) ([]entities.PurchaseOrder, error) {
+	return nil, nil
+}
+
+func (f *fakePurchaseOrderRepository) GetPending(ctx context.Context) ([]entities.PurchaseOrder, error) {
+	return nil, nil
+}
+
+func (f *fakePurchaseOrderRepository) GetReceipts(ctx context.Context, id uuid.UUID) ([]entities.PurchaseReceipt, error) {
+	return nil, nil
+}
+
+func (f *fakePurchaseOrderRepository) Close(ctx context.Context, id uuid.UUID) (entities.PurchaseOrder, error) {
+	return f.order, f.err
+}
+
+func (f *fakePurchaseOrderRepository) Receive(ctx context.Context, id, locationID uuid.UUID, items []repositories.ReceiptItem, info repositories.MovementInfo) (entities.PurchaseOrder, entities.PurchaseReceipt, error) {
+	f.calls++
+	f.items, f.info, f.target = items, info, locationID
+	if f.err != nil {
+		return entities.PurchaseOrder{}, entities.PurchaseReceipt{}, f.err
+	}
+	receipt := entities.PurchaseReceipt{ID: uuid.New(), PurchaseOrderID: id, LocationID: locationID}
+	for _, item := range items {
+		receipt.Lines = append(receipt.Lines, entities.PurchaseReceiptLine{ReceiptID: receipt.ID, LineID: uuid.New(), Received: item.Received, Damaged: item.Damaged})
+	}
+	return f.order, receipt, nil
+}
+
+func TestPurchaseOrderReceive(t *testing.T) {
+	first, second, edition := uuid.New(), uuid.New(), uuid.New()
+	order := entities.PurchaseOrder{ID: uuid.New(), Status: entities.PurchaseOrderPartial, ExpectedAt: time.Now(), Lines: []entities.PurchaseOrderLine{
+		{ID: uuid.New(), BookID: first, ExpectedQuantity: 10, ReceivedQuantity: 11, DamagedQuantity: 1},
+		{ID: uuid.New(), BookID: second, ExpectedQuantity: 4, DamagedQuantity: 1},
+	}}
+	repo := &fakePurchaseOrderRepository{order: order}
+	actor := models.Actor{UserID: uuid.New(), CorrelationID: "req-1"}
+	location := uuid.New()
+
+	response, inError := NewPurchaseOrderService(repo).Receive(context.Background(), order.ID, models.ReceivePurchaseOrderRequest{
+		LocationID: location,
+		Comment:    "first truck",
+		Lines: []models.ReceivePurchaseOrderLine{
+			{BookID: first, EditionID: edition, Received: 11, Damaged: 1},
+			{BookID: second, Damaged: 1},
+		},
+	}, actor)
+	if inError != nil {
+		t.Fatalf("receive: %v", inError)
+	}
+
+	wantItems := []repositories.ReceiptItem{{BookID: first, EditionID: edition, Received: 11, Damaged: 1}, {BookID: second, Damaged: 1}}
+	if fmt.Sprint(repo.items) != fmt.Sprint(wantItems) || repo.target != location {
+		t.Errorf("items = %+v to %s, want %+v to %s", repo.items, repo.target, wantItems, location)
+	}
+	if repo.info.ActorID != actor.UserID || repo.info.CorrelationID != "req-1" || repo.info.Comment != "first truck" {
+		t.Errorf("movement info = %+v", repo.info)
+	}
+
+	if response.PurchaseOrder.Status != entities.PurchaseOrderPartial || len(response.PurchaseOrder.Lines) != 2 {
+		t.Fatalf("order = %+v", response.PurchaseOrder)
+	}
+	// перепоставка сверх ожидаемого считается вместе с повреждёнными единицами
+	if over := response.PurchaseOrder.Lines[0]; over.OverReceived != 2 || over.Outstanding != 0 {
+		t.Errorf("over-received line = %+v, want 2 over, 0 outstanding", over)
+	}
+	if short := response.PurchaseOrder.Lines[1]; short.OverReceived != 0 || short.Outstanding != 3 {
+		t.Errorf("short line = %+v, want 0 over, 3 outstanding", short)
+	}
+	if len(response.Receipt.Lines) != 2 || response.Receipt.LocationID != location {
+		t.Errorf("receipt = %+v", response.Receipt)
+	}
+}
+
+func TestPurchaseOrderReceiveRejectsInvalidLines(t *testing.T) {
+	bookID := uuid.New()
+	cases := []struct {
+		name  string
+		lines []models.ReceivePurchaseOrderLine
+		code  string
+	}{
+		{"empty line", []models.ReceivePurchaseOrderLine{{BookID: bookID}}, "empty_receipt_line"},
+		{"duplicated book", []models.ReceivePurchaseOrderLine{{BookID: bookID, Received: 1}, {BookID: bookID, Damaged: 1}}, "duplicated_purchase_order_line"},
+	}
+	for _, tc := range cases {
+		repo := &fakePurchaseOrderRepository{}
+		_, inError := NewPurchaseOrderService(repo).Receive(context.Background(), uuid.New(), models.ReceivePurchaseOrderRequest{LocationID: uuid.New(), Lines: tc.lines}, models.Actor{})
+		if code := apperrors.From(inError).Code; code != tc.code || !apperrors.Is(inError, apperrors.KindValidation) {
+			t.Errorf("%s: error = %v, want validation %s", tc.name, inError, tc.code)
+		}
+		if repo.calls != 0 {
+			t.Errorf("%s: invalid receipt reached the repository", tc.name)
+		}
+	}
+}
+
+func TestPurchaseOrderReceiveMapsRepositoryErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		kind apperrors.Kind
+		code string
+	}{
+		{gorm.ErrRecordNotFound, apperrors.KindNotFound, "purchase_order_not_found"},
+		{repositories.ErrPurchaseOrderNotOpen, apperrors.KindConflict, "purchase_order_not_open"},
+		{fmt.Errorf("book x: %w", repositories.ErrBookNotOnPurchaseOrder), apperrors.KindValidation, "invalid_receipt_line"},
+		{fmt.Errorf("book x: %w", repositories.ErrUnknownEdition), apperrors.KindValidation, "invalid_receipt_line"},
+		{fmt.Errorf("book x: %w", repositories.ErrEditionRequired), apperrors.KindValidation, "invalid_receipt_line"},
+		{repositories.ErrUnknownLocation, apperrors.KindNotFound, "location_not_found"},
+		{errors.New("connection reset"), apperrors.KindInternal, ""},
+	}
+	for _, tc := range cases {
+		repo := &fakePurchaseOrderRepository{err: tc.err}
+		_, inError := NewPurchaseOrderService(repo).Receive(context.Background(), uuid.New(), models.ReceivePurchaseOrderRequest{
+			LocationID: uuid.New(),
+			Lines:      []models.ReceivePurchaseOrderLine{{BookID: uuid.New(), Received: 1}},
+		}, models.Actor{})
+		if !apperrors.Is(inError, tc.kind) || (tc.code != "" && apperrors.From(inError).Code != tc.code) {
+			t.Errorf("%v: error = %v, want %s %s", tc.err, inError, tc.kind, tc.code)
+		}
+	}
+}
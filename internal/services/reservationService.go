@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+type ReservationServiceInterface interface {
+	Create(ctx context.Context, reservation models.CreateReservationRequest, actor models.Actor) (models.Reservation, error)
+	FindById(ctx context.Context, id uuid.UUID) (models.Reservation, error)
+	Confirm(ctx context.Context, id uuid.UUID, actor models.Actor) (models.Reservation, error)
+	Cancel(ctx context.Context, id uuid.UUID) (models.Reservation, error)
+	ExpireOverdue(ctx context.Context) (int64, error)
+}
+
+type reservationService struct {
+	reservationRepo repositories.ReservationRepositoryInterface
+	ttl             time.Duration
+}
+
+func NewReservationService(reservationRepo repositories.ReservationRepositoryInterface, ttl time.Duration) ReservationServiceInterface {
+	return &reservationService{reservationRepo: reservationRepo, ttl: ttl}
+}
+
+func (r *reservationService) Create(ctx context.Context, reservation models.CreateReservationRequest, actor models.Actor) (models.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "ReservationService.Create")
+	defer span.End()
+
+	reservationEntity := entities.Reservation{
+		BookID:    reservation.BookID,
+		Quantity:  reservation.Quantity,
+		Reference: reservation.Reference,
+		ExpiresAt: time.Now().Add(r.ttl),
+	}
+	if actor.UserID != uuid.Nil {
+		reservationEntity.CreatedBy = &actor.UserID
+	}
+	newReservation, err := r.reservationRepo.Create(ctx, reservationEntity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Reservation{}, bookNotFound(reservation.BookID)
+		}
+		if errors.Is(err, repositories.ErrInsufficientStock) {
+			return models.Reservation{}, apperrors.InsufficientStock("insufficient_stock", fmt.Sprintf("not enough available stock to reserve %d copies", reservation.Quantity))
+		}
+		return models.Reservation{}, apperrors.Internal(err)
+	}
+	return toReservationModel(newReservation)
+}
+
+func (r *reservationService) FindById(ctx context.Context, id uuid.UUID) (models.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "ReservationService.FindById")
+	defer span.End()
+
+	reservation, err := r.reservationRepo.FindById(ctx, id)
+	if err != nil {
+		return models.Reservation{}, reservationError(id, err)
+	}
+	return toReservationModel(reservation)
+}
+
+func (r *reservationService) Confirm(ctx context.Context, id uuid.UUID, actor models.Actor) (models.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "ReservationService.Confirm")
+	defer span.End()
+
+	reservation, err := r.reservationRepo.Confirm(ctx, id, repositories.MovementInfo{
+		ActorID:       actor.UserID,
+		CorrelationID: actor.CorrelationID,
+	})
+	if err != nil {
+		return models.Reservation{}, reservationError(id, err)
+	}
+	return toReservationModel(reservation)
+}
+
+func (r *reservationService) Cancel(ctx context.Context, id uuid.UUID) (models.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "ReservationService.Cancel")
+	defer span.End()
+
+	reservation, err := r.reservationRepo.Cancel(ctx, id)
+	if err != nil {
+		return models.Reservation{}, reservationError(id, err)
+	}
+	return toReservationModel(reservation)
+}
+
+func (r *reservationService) ExpireOverdue(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "ReservationService.ExpireOverdue")
+	defer span.End()
+
+	return r.reservationRepo.ExpireOverdue(ctx)
+}
+
+func toReservationModel(reservation entities.Reservation) (models.Reservation, error) {
+	var reservationResult models.Reservation
+	if err := copier.Copy(&reservationResult, &reservation); err != nil {
+		return models.Reservation{}, apperrors.Internal(err)
+	}
+	return reservationResult, nil
+}
+
+func reservationError(id uuid.UUID, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperrors.NotFound("reservation_not_found", fmt.Sprintf("reservation with id = %s not found", id.String()))
+	case errors.Is(err, repositories.ErrReservationNotActive):
+		return apperrors.Conflict("reservation_not_active", "reservation is not active")
+	case errors.Is(err, repositories.ErrInsufficientStock):
+		return apperrors.InsufficientStock("insufficient_stock", "not enough stock to confirm reservation")
+	default:
+		return apperrors.Internal(err)
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// fakeReservationRepository держит остатки и резервы в памяти и, как и репозиторий,
+// резервирует только доступное: остаток минус активные резервы
+type fakeReservationRepository struct {
+	mu           sync.Mutex
+	onHand       map[uuid.UUID]int
+	reservations map[uuid.UUID]entities.Reservation
+}
+
+func newFakeReservationRepository(onHand map[uuid.UUID]int) *fakeReservationRepository {
+	return &fakeReservationRepository{onHand: onHand, reservations: make(map[uuid.UUID]entities.Reservation)}
+}
+
+func (f *fakeReservationRepository) available(bookID uuid.UUID) int {
+	available := f.onHand[bookID]
+	for _, reservation := range f.reservations {
+		if reservation.BookID == bookID && reservation.Status == entities.ReservationActive {
+			available -= reservation.Quantity
+		}
+	}
+	return available
+}
+
+func (f *fakeReservationRepository) Create(ctx context.Context, reservation entities.Reservation) (entities.Reservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.onHand[reservation.BookID]; !ok {
+		return entities.Reservation{}, gorm.ErrRecordNotFound
+	}
+	if f.available(reservation.BookID) < reservation.Quantity {
+		return entities.Reservation{}, repositories.ErrInsufficientStock
+	}
+	reservation.ID = uuid.New()
+	reservation.Status = entities.ReservationActive
+	f.reservations[reservation.ID] = reservation
+	return reservation, nil
+}
+
+func (f *fakeReservationRepository) FindById(ctx context.Context, id uuid.UUID) (entities.Reservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reservation, ok := f.reservations[id]
+	if !ok {
+		return entities.Reservation{}, gorm.ErrRecordNotFound
+	}
+	return reservation, nil
+}
+
+func (f *fakeReservationRepository) setStatus(id uuid.UUID, status string) (entities.Reservation, error) {
+	reservation, ok := f.reservations[id]
+	if !ok {
+		return entities.Reservation{}, gorm.ErrRecordNotFound
+	}
+	if reservation.Status != entities.ReservationActive {
+		return entities.Reservation{}, repositories.ErrReservationNotActive
+	}
+	reservation.Status = status
+	f.reservations[id] = reservation
+	return reservation, nil
+}
+
+func (f *fakeReservationRepository) Confirm(ctx context.Context, id uuid.UUID, info repositories.MovementInfo) (entities.Reservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reservation, err := f.setStatus(id, entities.ReservationConfirmed)
+	if err == nil {
+		f.onHand[reservation.BookID] -= reservation.Quantity
+	}
+	return reservation, err
+}
+
+func (f *fakeReservationRepository) Cancel(ctx context.Context, id uuid.UUID) (entities.Reservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.setStatus(id, entities.ReservationCancelled)
+}
+
+func (f *fakeReservationRepository) ExpireOverdue(ctx context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var expired int64
+	for id, reservation := range f.reservations {
+		if reservation.Status == entities.ReservationActive && reservation.ExpiresAt.Before(time.Now()) {
+			reservation.Status = entities.ReservationExpired
+			f.reservations[id] = reservation
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func TestReservationLifecycle(t *testing.T) {
+	bookID := uuid.New()
+	repo := newFakeReservationRepository(map[uuid.UUID]int{bookID: 5})
+	service := NewReservationService(repo, time.Hour)
+	ctx := context.Background()
+	actor := models.Actor{UserID: uuid.New()}
+
+	first, inError := service.Create(ctx, models.CreateReservationRequest{BookID: bookID, Quantity: 3}, actor)
+	if inError != nil || first.Status != entities.ReservationActive || first.CreatedBy == nil || *first.CreatedBy != actor.UserID {
+		t.Fatalf("create: %+v, %v", first, inError)
+	}
+	if time.Until(first.ExpiresAt) < 59*time.Minute {
+		t.Errorf("create: expires at %v, want now + ttl", first.ExpiresAt)
+	}
+
+	// доступно 2 из 5: резерв на 3 больше доступного
+	_, inError = service.Create(ctx, models.CreateReservationRequest{BookID: bookID, Quantity: 3}, actor)
+	if problem := apperrors.From(inError); problem.Code != "insufficient_stock" || apperrors.Status(problem.Kind) != http.StatusConflict {
+		t.Errorf("create over available: error = %v, want 409 insufficient_stock", inError)
+	}
+	if _, inError = service.Create(ctx, models.CreateReservationRequest{BookID: uuid.New(), Quantity: 1}, actor); !apperrors.Is(inError, apperrors.KindNotFound) {
+		t.Errorf("create for unknown book: error = %v, want not found", inError)
+	}
+
+	confirmed, inError := service.Confirm(ctx, first.ID, actor)
+	if inError != nil || confirmed.Status != entities.ReservationConfirmed || repo.onHand[bookID] != 2 {
+		t.Fatalf("confirm: %+v, %v, on hand %d", confirmed, inError, repo.onHand[bookID])
+	}
+	if _, inError = service.Cancel(ctx, first.ID); apperrors.From(inError).Code != "reservation_not_active" {
+		t.Errorf("cancel confirmed: error = %v, want reservation_not_active", inError)
+	}
+
+	second, inError := service.Create(ctx, models.CreateReservationRequest{BookID: bookID, Quantity: 2}, actor)
+	if inError != nil {
+		t.Fatalf("create second: %v", inError)
+	}
+	cancelled, inError := service.Cancel(ctx, second.ID)
+	if inError != nil || cancelled.Status != entities.ReservationCancelled {
+		t.Fatalf("cancel: %+v, %v", cancelled, inError)
+	}
+	// отменённый резерв возвращает количество в доступное
+	if _, inError = service.Create(ctx, models.CreateReservationRequest{BookID: bookID, Quantity: 2}, actor); inError != nil {
+		t.Errorf("create after cancel: %v", inError)
+	}
+	if _, inError = service.Confirm(ctx, uuid.New(), actor); !apperrors.Is(inError, apperrors.KindNotFound) {
+		t.Errorf("confirm unknown: error = %v, want not found", inError)
+	}
+}
+
+func TestReservationSweeperExpiresOverdue(t *testing.T) {
+	bookID := uuid.New()
+	repo := newFakeReservationRepository(map[uuid.UUID]int{bookID: 5})
+	overdue, _ := repo.Create(context.Background(), entities.Reservation{BookID: bookID, Quantity: 1, ExpiresAt: time.Now().Add(-time.Minute)})
+	fresh, _ := repo.Create(context.Background(), entities.Reservation{BookID: bookID, Quantity: 1, ExpiresAt: time.Now().Add(time.Hour)})
+
+	log := zerolog.Nop()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewReservationSweeper(NewReservationService(repo, time.Hour), 10*time.Millisecond, &log)(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		reservation, _ := repo.FindById(context.Background(), overdue.ID)
+		if reservation.Status == entities.ReservationExpired {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("overdue reservation was not expired by the sweeper")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop after cancellation")
+	}
+	if reservation, _ := repo.FindById(context.Background(), fresh.ID); reservation.Status != entities.ReservationActive {
+		t.Errorf("fresh reservation status = %s, want active", reservation.Status)
+	}
+}
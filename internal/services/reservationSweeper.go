@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// NewReservationSweeper возвращает фоновую задачу, которая раз в interval помечает просроченные резервы
+func NewReservationSweeper(reservationService ReservationServiceInterface, interval time.Duration, logger *zerolog.Logger) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// проход не должен пересекаться со следующим, поэтому ограничен интервалом запуска
+				// логгер задачи передаётся в контексте, им же пишутся медленные и ошибочные запросы к базе
+				sweepCtx, cancel := context.WithTimeout(logger.WithContext(ctx), interval)
+				expired, err := reservationService.ExpireOverdue(sweepCtx)
+				cancel()
+				if err != nil {
+					logger.Error().Err(err).Msg("Cannot expire reservations")
+					continue
+				}
+				if expired > 0 {
+					logger.Info().Int64("expired", expired).Msg("Reservations expired")
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/textsearch"
+
+	"github.com/jinzhu/copier"
+)
+
+type SearchServiceInterface interface {
+	SearchBooks(ctx context.Context, query string, limit, offset int) (models.BookSearchResponse, error)
+	SearchAuthors(ctx context.Context, query string, limit, offset int) (models.AuthorSearchResponse, error)
+}
+
+type searchService struct {
+	searchRepo repositories.SearchRepositoryInterface
+}
+
+func NewSearchService(searchRepo repositories.SearchRepositoryInterface) SearchServiceInterface {
+	return &searchService{searchRepo: searchRepo}
+}
+
+func (s *searchService) SearchBooks(ctx context.Context, query string, limit, offset int) (models.BookSearchResponse, error) {
+	ctx, span := tracer.Start(ctx, "SearchService.SearchBooks")
+	defer span.End()
+
+	parsed, inError := parseSearchQuery(query)
+	if inError != nil {
+		return models.BookSearchResponse{}, inError
+	}
+	results, total, err := s.searchRepo.SearchBooks(ctx, parsed, limit, offset)
+	if err != nil {
+		return models.BookSearchResponse{}, apperrors.Internal(err)
+	}
+	response := models.BookSearchResponse{Total: total, Items: make([]models.BookSearchHit, 0, len(results))}
+	for _, result := range results {
+		book, err := toBookModel(result.Book)
+		if err != nil {
+			return models.BookSearchResponse{}, apperrors.Internal(err)
+		}
+		response.Items = append(response.Items, models.BookSearchHit{
+			Book: book,
+			Rank: result.Rank,
+			Highlights: models.BookHighlights{
+				Title:  result.TitleHighlight,
+				Author: result.AuthorHighlight,
+			},
+		})
+	}
+	return response, nil
+}
+
+func (s *searchService) SearchAuthors(ctx context.Context, query string, limit, offset int) (models.AuthorSearchResponse, error) {
+	ctx, span := tracer.Start(ctx, "SearchService.SearchAuthors")
+	defer span.End()
+
+	parsed, inError := parseSearchQuery(query)
+	if inError != nil {
+		return models.AuthorSearchResponse{}, inError
+	}
+	results, total, err := s.searchRepo.SearchAuthors(ctx, parsed, limit, offset)
+	if err != nil {
+		return models.AuthorSearchResponse{}, apperrors.Internal(err)
+	}
+	response := models.AuthorSearchResponse{Total: total, Items: make([]models.AuthorSearchHit, 0, len(results))}
+	for _, result := range results {
+		var author models.Author
+		if err := copier.Copy(&author, &result.Author); err != nil {
+			return models.AuthorSearchResponse{}, apperrors.Internal(err)
+		}
+		author.FullName = fullName(author)
+		response.Items = append(response.Items, models.AuthorSearchHit{
+			Author:    author,
+			Rank:      result.Rank,
+			Highlight: result.Highlight,
+		})
+	}
+	return response, nil
+}
+
+func parseSearchQuery(query string) (textsearch.Query, error) {
+	parsed := textsearch.Parse(query)
+	if parsed.Empty() {
+		return textsearch.Query{}, apperrors.Validation("empty_search_query", "search query must contain at least one word or initial").WithField("q", "must contain at least one word or initial")
+	}
+	return parsed, nil
+}
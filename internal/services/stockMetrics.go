@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gin_main/internal/repositories"
+	"gin_main/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// stockScrapeTimeout ограничивает запросы сводки остатков и итогов журнала при каждом обращении к /metrics
+const stockScrapeTimeout = 5 * time.Second
+
+// StockMetrics - бизнес-метрики склада. Всё читается из базы при сборе метрик: остатки - из stock_levels,
+// изменения количества - из журнала движения, куда попадают приёмки, продажи, резервы, перемещения и ручные правки
+type StockMetrics struct {
+	bookRepo       repositories.BookRepositoryInterface
+	movementRepo   repositories.StockMovementRepositoryInterface
+	units          *prometheus.Desc
+	zeroStockBooks *prometheus.Desc
+	changes        *prometheus.Desc
+	changedUnits   *prometheus.Desc
+}
+
+func NewStockMetrics(bookRepo repositories.BookRepositoryInterface, movementRepo repositories.StockMovementRepositoryInterface) *StockMetrics {
+	return &StockMetrics{
+		bookRepo:       bookRepo,
+		movementRepo:   movementRepo,
+		units:          prometheus.NewDesc("stock_units", "Units in stock across all locations, trash excluded.", nil, nil),
+		zeroStockBooks: prometheus.NewDesc("stock_zero_books", "Books with no units in any location, trash excluded.", nil, nil),
+		changes: prometheus.NewDesc("stock_quantity_changes_total",
+			"Stock movements recorded in the ledger by reason.", []string{"reason"}, nil),
+		changedUnits: prometheus.NewDesc("stock_quantity_changed_units_total",
+			"Units added or removed by stock movements, by reason and direction.", []string{"reason", "direction"}, nil),
+	}
+}
+
+func (m *StockMetrics) Describe(descs chan<- *prometheus.Desc) {
+	descs <- m.units
+	descs <- m.zeroStockBooks
+	descs <- m.changes
+	descs <- m.changedUnits
+}
+
+// Collect пропускает метрики, которые не удалось прочитать: недоступная база не должна ломать выдачу остальных
+func (m *StockMetrics) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), stockScrapeTimeout)
+	defer cancel()
+	log := logger.FromContext(ctx)
+
+	summary, err := m.bookRepo.StockSummary(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Cannot collect stock metrics")
+	} else {
+		metrics <- prometheus.MustNewConstMetric(m.units, prometheus.GaugeValue, float64(summary.Units))
+		metrics <- prometheus.MustNewConstMetric(m.zeroStockBooks, prometheus.GaugeValue, float64(summary.ZeroStockBooks))
+	}
+
+	totals, err := m.movementRepo.Totals(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Cannot collect stock movement metrics")
+		return
+	}
+	changes := make(map[string]int64)
+	for _, total := range totals {
+		changes[total.Reason] += total.Movements
+		metrics <- prometheus.MustNewConstMetric(m.changedUnits, prometheus.CounterValue, float64(total.Units), total.Reason, total.Direction)
+	}
+	for reason, count := range changes {
+		metrics <- prometheus.MustNewConstMetric(m.changes, prometheus.CounterValue, float64(count), reason)
+	}
+}
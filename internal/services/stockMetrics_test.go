@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gin_main/internal/repositories"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeStockSummaryRepository отвечает только на запрос сводки остатков
+type fakeStockSummaryRepository struct {
+	repositories.BookRepositoryInterface
+	summary repositories.StockSummary
+	err     error
+}
+
+func (f *fakeStockSummaryRepository) StockSummary(ctx context.Context) (repositories.StockSummary, error) {
+	return f.summary, f.err
+}
+
+// fakeMovementTotalsRepository отвечает только на запрос итогов журнала
+type fakeMovementTotalsRepository struct {
+	repositories.StockMovementRepositoryInterface
+	totals []repositories.MovementTotal
+}
+
+func (f *fakeMovementTotalsRepository) Totals(ctx context.Context) ([]repositories.MovementTotal, error) {
+	return f.totals, nil
+}
+
+func TestStockMetricsCountLedgerMovements(t *testing.T) {
+	movements := &fakeMovementTotalsRepository{totals: []repositories.MovementTotal{
+		{Reason: "receipt", Direction: "in", Movements: 3, Units: 40},
+		{Reason: "sale", Direction: "out", Movements: 5, Units: 7},
+		{Reason: "transfer", Direction: "in", Movements: 2, Units: 6},
+		{Reason: "transfer", Direction: "out", Movements: 2, Units: 6},
+	}}
+	metrics := NewStockMetrics(&fakeStockSummaryRepository{summary: repositories.StockSummary{Units: 33, ZeroStockBooks: 1}}, movements)
+
+	want := `
+# HELP stock_quantity_changed_units_total Units added or removed by stock movements, by reason and direction.
+# TYPE stock_quantity_changed_units_total counter
+stock_quantity_changed_units_total{direction="in",reason="receipt"} 40
+stock_quantity_changed_units_total{direction="in",reason="transfer"} 6
+stock_quantity_changed_units_total{direction="out",reason="sale"} 7
+stock_quantity_changed_units_total{direction="out",reason="transfer"} 6
+# HELP stock_quantity_changes_total Stock movements recorded in the ledger by reason.
+# TYPE stock_quantity_changes_total counter
+stock_quantity_changes_total{reason="receipt"} 3
+stock_quantity_changes_total{reason="sale"} 5
+stock_quantity_changes_total{reason="transfer"} 4
+# HELP stock_units Units in stock across all locations, trash excluded.
+# TYPE stock_units gauge
+stock_units 33
+`
+	if err := testutil.CollectAndCompare(metrics, strings.NewReader(want), "stock_units", "stock_quantity_changes_total", "stock_quantity_changed_units_total"); err != nil {
+		t.Error(err)
+	}
+
+	// без сводки остатков счётчики журнала всё равно отдаются
+	metrics = NewStockMetrics(&fakeStockSummaryRepository{err: errors.New("connection refused")}, movements)
+	if count := testutil.CollectAndCount(metrics); count != 7 {
+		t.Errorf("collected %d metrics with summary unavailable, want 7 ledger counters", count)
+	}
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/pkg/apperrors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+)
+
+type StockMovementServiceInterface interface {
+	GetByBook(ctx context.Context, bookID uuid.UUID, from, to *time.Time, limit, offset int) (models.StockMovementsResponse, error)
+}
+
+type stockMovementService struct {
+	stockMovementRepo repositories.StockMovementRepositoryInterface
+}
+
+func NewStockMovementService(stockMovementRepo repositories.StockMovementRepositoryInterface) StockMovementServiceInterface {
+	return &stockMovementService{stockMovementRepo: stockMovementRepo}
+}
+
+func (r *stockMovementService) GetByBook(ctx context.Context, bookID uuid.UUID, from, to *time.Time, limit, offset int) (models.StockMovementsResponse, error) {
+	ctx, span := tracer.Start(ctx, "StockMovementService.GetByBook")
+	defer span.End()
+
+	movementsEntities, total, err := r.stockMovementRepo.GetByBook(ctx, bookID, from, to, limit, offset)
+	if err != nil {
+		return models.StockMovementsResponse{}, apperrors.Internal(err)
+	}
+	movements := []models.StockMovement{}
+	if err = copier.Copy(&movements, &movementsEntities); err != nil {
+		return models.StockMovementsResponse{}, apperrors.Internal(err)
+	}
+	return models.StockMovementsResponse{Items: movements, Total: total}, nil
+}
@@ -0,0 +1,6 @@
+package services
+
+import "go.opentelemetry.io/otel"
+
+// tracer открывает дочерний спан на каждый метод сервиса, родителем служит спан запроса из TracingMiddleware
+var tracer = otel.Tracer("gin_main/internal/services")
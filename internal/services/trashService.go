@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/pkg/apperrors"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+type TrashServiceInterface interface {
+	Get(ctx context.Context) (models.Trash, error)
+	RestoreBook(ctx context.Context, id uuid.UUID) error
+	RestoreAuthor(ctx context.Context, id uuid.UUID) error
+	PurgeBook(ctx context.Context, id uuid.UUID) error
+	PurgeAuthor(ctx context.Context, id uuid.UUID) error
+}
+
+type trashService struct {
+	trashRepo repositories.TrashRepositoryInterface
+}
+
+func NewTrashService(trashRepo repositories.TrashRepositoryInterface) TrashServiceInterface {
+	return &trashService{trashRepo: trashRepo}
+}
+
+func (r *trashService) Get(ctx context.Context) (models.Trash, error) {
+	ctx, span := tracer.Start(ctx, "TrashService.Get")
+	defer span.End()
+
+	bookEntities, err := r.trashRepo.GetBooks(ctx)
+	if err != nil {
+		return models.Trash{}, apperrors.Internal(err)
+	}
+	authorEntities, err := r.trashRepo.GetAuthors(ctx)
+	if err != nil {
+		return models.Trash{}, apperrors.Internal(err)
+	}
+
+	trash := models.Trash{
+		Books:   make([]models.TrashedBook, 0, len(bookEntities)),
+		Authors: make([]models.TrashedAuthor, 0, len(authorEntities)),
+	}
+	for _, entity := range bookEntities {
+		book, err := toBookModel(entity)
+		if err != nil {
+			return models.Trash{}, apperrors.Internal(err)
+		}
+		trash.Books = append(trash.Books, models.TrashedBook{
+			Book:      book,
+			DeletedAt: entity.DeletedAt.Time,
+			DeletedBy: entity.DeletedBy,
+		})
+	}
+	for _, entity := range authorEntities {
+		var author models.Author
+		if err := copier.Copy(&author, &entity); err != nil {
+			return models.Trash{}, apperrors.Internal(err)
+		}
+		author.FullName = fullName(author)
+		trash.Authors = append(trash.Authors, models.TrashedAuthor{
+			Author:    author,
+			DeletedAt: entity.DeletedAt.Time,
+			DeletedBy: entity.DeletedBy,
+		})
+	}
+	return trash, nil
+}
+
+func (r *trashService) RestoreBook(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "TrashService.RestoreBook")
+	defer span.End()
+
+	if err := r.trashRepo.RestoreBook(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return trashedBookNotFound(id)
+		}
+		if errors.Is(err, repositories.ErrContributorInTrash) {
+			return apperrors.Conflict("contributor_in_trash", fmt.Sprintf("book with id = %s has contributors in trash, restore them first", id.String()))
+		}
+		if errors.Is(err, repositories.ErrISBNExists) {
+			return apperrors.Conflict("isbn_exists", fmt.Sprintf("isbn of book with id = %s is already used by another book", id.String()))
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (r *trashService) RestoreAuthor(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "TrashService.RestoreAuthor")
+	defer span.End()
+
+	if err := r.trashRepo.RestoreAuthor(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return trashedAuthorNotFound(id)
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (r *trashService) PurgeBook(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "TrashService.PurgeBook")
+	defer span.End()
+
+	if err := r.trashRepo.PurgeBook(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return trashedBookNotFound(id)
+		}
+		if errors.Is(err, repositories.ErrBookHasHistory) {
+			return apperrors.Conflict("book_has_history", fmt.Sprintf("book with id = %s has stock, order or purchasing history and cannot be purged", id.String()))
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (r *trashService) PurgeAuthor(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "TrashService.PurgeAuthor")
+	defer span.End()
+
+	if err := r.trashRepo.PurgeAuthor(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return trashedAuthorNotFound(id)
+		}
+		if errors.Is(err, repositories.ErrAuthorHasBooks) {
+			return apperrors.Conflict("author_has_books", fmt.Sprintf("author with id = %s still has books, purge them first", id.String()))
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func trashedBookNotFound(id uuid.UUID) error {
+	return apperrors.NotFound("book_not_found", fmt.Sprintf("book with id = %s not found in trash", id.String()))
+}
+
+func trashedAuthorNotFound(id uuid.UUID) error {
+	return apperrors.NotFound("author_not_found", fmt.Sprintf("author with id = %s not found in trash", id.String()))
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// dummyPasswordHash сравнивается с паролем, когда логин не найден, чтобы время ответа не выдавало существование пользователя
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+type UserServiceInterface interface {
+	CheckCredentials(ctx context.Context, login, password string) (models.User, error)
+	FindById(ctx context.Context, id uuid.UUID) (models.User, error)
+	EnsureUser(ctx context.Context, login, password, role string) error // создаёт пользователя при старте, если его ещё нет
+	Create(ctx context.Context, user models.CreateUserRequest) (models.CreateUserResponse, error)
+	GetAll(ctx context.Context) ([]models.User, error)
+	SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error
+	ResetPassword(ctx context.Context, id uuid.UUID, request models.ResetPasswordRequest) error
+	ChangePassword(ctx context.Context, id uuid.UUID, request models.ChangePasswordRequest) error
+}
+
+type userService struct {
+	userRepo repositories.UserRepositoryInterface
+}
+
+func NewUserService(userRepo repositories.UserRepositoryInterface) UserServiceInterface {
+	return &userService{userRepo: userRepo}
+}
+
+func (r *userService) CheckCredentials(ctx context.Context, login, password string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.CheckCredentials")
+	defer span.End()
+
+	invalidCredentials := apperrors.Unauthorized("invalid_credentials", "invalid login or password")
+	userFound, err := r.userRepo.FindByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+			return models.User{}, invalidCredentials
+		}
+		return models.User{}, apperrors.Internal(err)
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(userFound.PasswordHash), []byte(password)); err != nil {
+		return models.User{}, invalidCredentials
+	}
+	if userFound.Disabled {
+		return models.User{}, userDisabled()
+	}
+	lastLoginAt := time.Now()
+	if err = r.userRepo.SetLastLogin(ctx, userFound.ID, lastLoginAt); err != nil {
+		return models.User{}, apperrors.Internal(err)
+	}
+	userFound.LastLoginAt = &lastLoginAt
+	return toUserModel(userFound), nil
+}
+
+func (r *userService) FindById(ctx context.Context, id uuid.UUID) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.FindById")
+	defer span.End()
+
+	userFound, err := r.userRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, userNotFound(id)
+		}
+		return models.User{}, apperrors.Internal(err)
+	}
+	return toUserModel(userFound), nil
+}
+
+func (r *userService) EnsureUser(ctx context.Context, login, password, role string) error {
+	ctx, span := tracer.Start(ctx, "UserService.EnsureUser")
+	defer span.End()
+
+	_, err := r.userRepo.FindByLogin(ctx, login)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	_, err = r.userRepo.Create(ctx, entities.User{Login: login, PasswordHash: string(passwordHash), Role: role})
+	if errors.Is(err, repositories.ErrUserExists) {
+		return nil
+	}
+	return err
+}
+
+func (r *userService) Create(ctx context.Context, user models.CreateUserRequest) (models.CreateUserResponse, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Create")
+	defer span.End()
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.CreateUserResponse{}, apperrors.Internal(err)
+	}
+	newUser, err := r.userRepo.Create(ctx, entities.User{Login: user.Login, PasswordHash: string(passwordHash), Role: user.Role})
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserExists) {
+			return models.CreateUserResponse{}, apperrors.Conflict("login_exists", fmt.Sprintf("user with login = %s already exists", user.Login))
+		}
+		return models.CreateUserResponse{}, apperrors.Internal(err)
+	}
+	return models.CreateUserResponse{ID: newUser.ID}, nil
+}
+
+func (r *userService) GetAll(ctx context.Context) ([]models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetAll")
+	defer span.End()
+
+	usersEntities, err := r.userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	users := []models.User{}
+	if err = copier.Copy(&users, &usersEntities); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return users, nil
+}
+
+func (r *userService) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	ctx, span := tracer.Start(ctx, "UserService.SetDisabled")
+	defer span.End()
+
+	if err := r.userRepo.SetDisabled(ctx, id, disabled); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return userNotFound(id)
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (r *userService) ResetPassword(ctx context.Context, id uuid.UUID, request models.ResetPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "UserService.ResetPassword")
+	defer span.End()
+
+	return r.setPassword(ctx, id, request.Password)
+}
+
+func (r *userService) ChangePassword(ctx context.Context, id uuid.UUID, request models.ChangePasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	userFound, err := r.userRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return userNotFound(id)
+		}
+		return apperrors.Internal(err)
+	}
+	if userFound.Disabled {
+		return userDisabled()
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(userFound.PasswordHash), []byte(request.CurrentPassword)); err != nil {
+		return apperrors.Validation("wrong_password", "current password is wrong").WithField("currentPassword", "does not match")
+	}
+	return r.setPassword(ctx, id, request.NewPassword)
+}
+
+func (r *userService) setPassword(ctx context.Context, id uuid.UUID, password string) error {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if err = r.userRepo.SetPasswordHash(ctx, id, string(passwordHash)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return userNotFound(id)
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func toUserModel(user entities.User) models.User {
+	return models.User{
+		ID:               user.ID,
+		Login:            user.Login,
+		Role:             user.Role,
+		Disabled:         user.Disabled,
+		LastLoginAt:      user.LastLoginAt,
+		TokensValidAfter: user.TokensValidAfter,
+		CreatedAt:        user.CreatedAt,
+	}
+}
+
+func userNotFound(id uuid.UUID) error {
+	return apperrors.NotFound("user_not_found", fmt.Sprintf("user with id = %s not found", id.String()))
+}
+
+func userDisabled() error {
+	return apperrors.Forbidden("user_disabled", "user is disabled")
+}
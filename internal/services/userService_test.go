@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"gin_main/internal/models"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestUser(t *testing.T, login, password string) entities.User {
+	t.Helper()
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entities.User{ID: uuid.New(), Login: login, PasswordHash: string(passwordHash), Role: models.RoleClerk}
+}
+
+func TestUserSetDisabled(t *testing.T) {
+	user := newTestUser(t, "clerk", "old-password")
+	service := NewUserService(newFakeUserRepository(user))
+	ctx := context.Background()
+
+	if inError := service.SetDisabled(ctx, user.ID, true); inError != nil {
+		t.Fatalf("disable: %v", inError)
+	}
+	if _, inError := service.CheckCredentials(ctx, "clerk", "old-password"); apperrors.From(inError).Code != "user_disabled" {
+		t.Errorf("login of disabled user: error = %v, want user_disabled", inError)
+	}
+	if inError := service.SetDisabled(ctx, user.ID, false); inError != nil {
+		t.Fatalf("enable: %v", inError)
+	}
+	if _, inError := service.CheckCredentials(ctx, "clerk", "old-password"); inError != nil {
+		t.Errorf("login of enabled user: %v", inError)
+	}
+	if inError := service.SetDisabled(ctx, uuid.New(), true); apperrors.From(inError).Code != "user_not_found" {
+		t.Errorf("disable unknown user: error = %v, want user_not_found", inError)
+	}
+}
+
+func TestUserResetPassword(t *testing.T) {
+	user := newTestUser(t, "clerk", "old-password")
+	repo := newFakeUserRepository(user)
+	service := NewUserService(repo)
+	ctx := context.Background()
+
+	if inError := service.ResetPassword(ctx, user.ID, models.ResetPasswordRequest{Password: "new-password"}); inError != nil {
+		t.Fatalf("reset: %v", inError)
+	}
+	if repo.users[user.ID].PasswordHash == user.PasswordHash {
+		t.Fatal("reset kept the old password hash")
+	}
+	if _, inError := service.CheckCredentials(ctx, "clerk", "old-password"); !apperrors.Is(inError, apperrors.KindUnauthorized) {
+		t.Errorf("login with old password: error = %v, want unauthorized", inError)
+	}
+	if _, inError := service.CheckCredentials(ctx, "clerk", "new-password"); inError != nil {
+		t.Errorf("login with new password: %v", inError)
+	}
+	if inError := service.ResetPassword(ctx, uuid.New(), models.ResetPasswordRequest{Password: "new-password"}); apperrors.From(inError).Code != "user_not_found" {
+		t.Errorf("reset unknown user: error = %v, want user_not_found", inError)
+	}
+}
+
+func TestUserChangePassword(t *testing.T) {
+	user := newTestUser(t, "clerk", "old-password")
+	disabled := newTestUser(t, "former", "old-password")
+	disabled.Disabled = true
+	repo := newFakeUserRepository(user, disabled)
+	service := NewUserService(repo)
+	ctx := context.Background()
+
+	inError := service.ChangePassword(ctx, user.ID, models.ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: "new-password"})
+	problem := apperrors.From(inError)
+	if problem.Code != "wrong_password" || !apperrors.Is(inError, apperrors.KindValidation) || len(problem.Fields) != 1 || problem.Fields[0].Field != "currentPassword" {
+		t.Errorf("wrong current password: error = %+v, want wrong_password on currentPassword", problem)
+	}
+	if repo.users[user.ID].PasswordHash != user.PasswordHash {
+		t.Error("wrong current password changed the hash")
+	}
+
+	inError = service.ChangePassword(ctx, disabled.ID, models.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "new-password"})
+	if apperrors.From(inError).Code != "user_disabled" || !apperrors.Is(inError, apperrors.KindForbidden) {
+		t.Errorf("disabled user: error = %v, want user_disabled", inError)
+	}
+	inError = service.ChangePassword(ctx, uuid.New(), models.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "new-password"})
+	if apperrors.From(inError).Code != "user_not_found" {
+		t.Errorf("unknown user: error = %v, want user_not_found", inError)
+	}
+
+	if inError = service.ChangePassword(ctx, user.ID, models.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "new-password"}); inError != nil {
+		t.Fatalf("change: %v", inError)
+	}
+	if _, inError = service.CheckCredentials(ctx, "clerk", "new-password"); inError != nil {
+		t.Errorf("login with new password: %v", inError)
+	}
+}
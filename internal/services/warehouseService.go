@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin_main/internal/models"
+	"gin_main/internal/repositories"
+	"gin_main/internal/repositories/entities"
+	"gin_main/pkg/apperrors"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+)
+
+type WarehouseServiceInterface interface {
+	CreateWarehouse(ctx context.Context, warehouse models.CreateWarehouseRequest) (models.CreateWarehouseResponse, error)
+	GetAllWarehouses(ctx context.Context) ([]models.Warehouse, error)
+	CreateLocation(ctx context.Context, warehouseID uuid.UUID, location models.CreateLocationRequest) (models.CreateLocationResponse, error)
+	GetLocations(ctx context.Context, warehouseID uuid.UUID) ([]models.Location, error)
+	GetBookStock(ctx context.Context, bookID uuid.UUID) (models.BookStockResponse, error)
+	GetWarehouseStock(ctx context.Context, warehouseID uuid.UUID) (models.WarehouseStock, error)
+	MoveStock(ctx context.Context, move models.MoveStockRequest, actor models.Actor) error
+}
+
+type warehouseService struct {
+	warehouseRepo repositories.WarehouseRepositoryInterface
+}
+
+func NewWarehouseService(warehouseRepo repositories.WarehouseRepositoryInterface) WarehouseServiceInterface {
+	return &warehouseService{warehouseRepo: warehouseRepo}
+}
+
+func (r *warehouseService) CreateWarehouse(ctx context.Context, warehouse models.CreateWarehouseRequest) (models.CreateWarehouseResponse, error) {
+	ctx, span := tracer.Start(ctx, "WarehouseService.CreateWarehouse")
+	defer span.End()
+
+	newWarehouse, err := r.warehouseRepo.CreateWarehouse(ctx, entities.Warehouse{
+		Code:    warehouse.Code,
+		Name:    warehouse.Name,
+		Address: warehouse.Address,
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrWarehouseExists) {
+			return models.CreateWarehouseResponse{}, apperrors.Conflict("warehouse_exists", fmt.Sprintf("warehouse with code = %s already exists", warehouse.Code))
+		}
+		return models.CreateWarehouseResponse{}, apperrors.Internal(err)
+	}
+	return models.CreateWarehouseResponse{ID: newWarehouse.ID}, nil
+}
+
+func (r *warehouseService) GetAllWarehouses(ctx context.Context) ([]models.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "WarehouseService.GetAllWarehouses")
+	defer span.End()
+
+	warehousesEntities, err := r.warehouseRepo.GetAllWarehouses(ctx)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	warehouses := []models.Warehouse{}
+	if err = copier.Copy(&warehouses, &warehousesEntities); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return warehouses, nil
+}
+
+func (r *warehouseService) CreateLocation(ctx context.Context, warehouseID uuid.UUID, location models.CreateLocationRequest) (models.CreateLocationResponse, error) {
+	ctx, span := tracer.Start(ctx, "WarehouseService.CreateLocation")
+	defer span.End()
+
+	newLocation, err := r.warehouseRepo.CreateLocation(ctx, entities.Location{
+		WarehouseID: warehouseID,
+		Aisle:       location.Aisle,
+		Shelf:       location.Shelf,
+		Bin:         location.Bin,
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CreateLocationResponse{}, warehouseNotFound(warehouseID)
+		}
+		if errors.Is(err, repositories.ErrLocationExists) {
+			return models.CreateLocationResponse{}, apperrors.Conflict("location_exists", "location with this aisle, shelf and bin already exists")
+		}
+		return models.CreateLocationResponse{}, apperrors.Internal(err)
+	}
+	return models.CreateLocationResponse{ID: newLocation.ID}, nil
+}
+
+func (r *warehouseService) GetLocations(ctx context.Context, warehouseID uuid.UUID) ([]models.Location, error) {
+	ctx, span := tracer.Start(ctx, "WarehouseService.GetLocations")
+	defer span.End()
+
+	if _, inError := r.findWarehouse(ctx, warehouseID); inError != nil {
+		return nil, inError
+	}
+	locationsEntities, err := r.warehouseRepo.GetLocations(ctx, warehouseID)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	locations := []models.Location{}
+	if err = copier.Copy(&locations, &locationsEntities); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return locations, nil
+}
+
+func (r *warehouseService) GetBookStock(ctx context.Context, bookID uuid.UUID) (models.BookStockResponse, error) {
+	ctx, span := tracer.Start(ctx, "WarehouseService.GetBookStock")
+	defer span.End()
+
+	stock, err := r.warehouseRepo.GetBookStock(ctx, bookID)
+	if err != nil {
+		return models.BookStockResponse{}, apperrors.Internal(err)
+	}
+	response := models.BookStockResponse{BookID: bookID, Warehouses: []models.WarehouseStock{}}
+	byWarehouse := make(map[uuid.UUID]int)
+	for _, level := range stock {
+		index, exists := byWarehouse[level.Location.WarehouseID]
+		if !exists {
+			index = len(response.Warehouses)
+			byWarehouse[level.Location.WarehouseID] = index
+			response.Warehouses = append(response.Warehouses, models.WarehouseStock{
+				Warehouse: toWarehouseModel(level.Location.Warehouse),
+				Locations: []models.StockLevel{},
+			})
+		}
+		response.Warehouses[index].Quantity += level.Quantity
+		response.Warehouses[index].Locations = append(response.Warehouses[index].Locations, toStockLevelModel(level))
+		response.Quantity += level.Quantity
+	}
+	return response, nil
+}
+
+func (r *warehouseService) GetWarehouseStock(ctx context.Context, warehouseID uuid.UUID) (models.WarehouseStock, error) {
+	ctx, span := tracer.Start(ctx, "WarehouseService.GetWarehouseStock")
+	defer span.End()
+
+	warehouse, inError := r.findWarehouse(ctx, warehouseID)
+	if inError != nil {
+		return models.WarehouseStock{}, inError
+	}
+	stock, err := r.warehouseRepo.GetWarehouseStock(ctx, warehouseID)
+	if err != nil {
+		return models.WarehouseStock{}, apperrors.Internal(err)
+	}
+	response := models.WarehouseStock{Warehouse: toWarehouseModel(warehouse), Locations: make([]models.StockLevel, 0, len(stock))}
+	for _, level := range stock {
+		response.Quantity += level.Quantity
+		response.Locations = append(response.Locations, toStockLevelModel(level))
+	}
+	return response, nil
+}
+
+func (r *warehouseService) MoveStock(ctx context.Context, move models.MoveStockRequest, actor models.Actor) error {
+	ctx, span := tracer.Start(ctx, "WarehouseService.MoveStock")
+	defer span.End()
+
+	if move.FromLocationID == move.ToLocationID {
+		return apperrors.Validation("same_location", "source and destination locations must differ").WithField("toLocationId", "must differ from fromLocationId")
+	}
+	info := repositories.MovementInfo{ActorID: actor.UserID, CorrelationID: actor.CorrelationID, Comment: move.Comment}
+	if err := r.warehouseRepo.MoveStock(ctx, move.BookID, move.EditionID, move.FromLocationID, move.ToLocationID, move.Quantity, info); err != nil {
+		if errors.Is(err, repositories.ErrInsufficientStock) {
+			return apperrors.InsufficientStock("insufficient_stock", "not enough stock in source location")
+		}
+		if errors.Is(err, repositories.ErrEditionRequired) {
+			return editionRequired()
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("stock_target_not_found", "book, edition or destination location not found")
+		}
+		return apperrors.Internal(err)
+	}
+	return nil
+}
+
+func (r *warehouseService) findWarehouse(ctx context.Context, id uuid.UUID) (entities.Warehouse, error) {
+	warehouse, err := r.warehouseRepo.FindWarehouseById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Warehouse{}, warehouseNotFound(id)
+		}
+		return entities.Warehouse{}, apperrors.Internal(err)
+	}
+	return warehouse, nil
+}
+
+func toWarehouseModel(warehouse entities.Warehouse) models.Warehouse {
+	return models.Warehouse{ID: warehouse.ID, Code: warehouse.Code, Name: warehouse.Name, Address: warehouse.Address}
+}
+
+func toStockLevelModel(level entities.StockLevel) models.StockLevel {
+	return models.StockLevel{
+		BookID:    level.BookID,
+		EditionID: level.EditionID,
+		Location: models.Location{
+			ID:          level.Location.ID,
+			WarehouseID: level.Location.WarehouseID,
+			Aisle:       level.Location.Aisle,
+			Shelf:       level.Location.Shelf,
+			Bin:         level.Location.Bin,
+		},
+		Quantity: level.Quantity,
+	}
+}
+
+func warehouseNotFound(id uuid.UUID) error {
+	return apperrors.NotFound("warehouse_not_found", fmt.Sprintf("warehouse with id = %s not found", id.String()))
+}
@@ -0,0 +1,167 @@
+// Package apperrors - каталог ошибок предметной области. Сервисы возвращают *Error с видом ошибки
+// и стабильным машиночитаемым кодом, а HTTP-статус и тело ответа выбираются в одном месте, по виду ошибки
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Kind - вид ошибки; определяет HTTP-статус и поле type ответа application/problem+json
+type Kind string
+
+const (
+	KindValidation           Kind = "validation"
+	KindUnauthorized         Kind = "unauthorized"
+	KindForbidden            Kind = "forbidden"
+	KindNotFound             Kind = "not-found"
+	KindConflict             Kind = "conflict"
+	KindInsufficientStock    Kind = "insufficient-stock"
+	KindPreconditionFailed   Kind = "precondition-failed"
+	KindPreconditionRequired Kind = "precondition-required"
+	KindUnprocessable        Kind = "unprocessable"
+	KindPayloadTooLarge      Kind = "payload-too-large"
+	KindTimeout              Kind = "timeout"
+	KindInternal             Kind = "internal"
+)
+
+// FieldError описывает ошибку в конкретном поле запроса
+type FieldError struct {
+	Field   string `json:"field"` // путь к полю в терминах JSON: contributors[1].role
+	Message string `json:"message"`
+}
+
+// Error - ошибка предметной области
+type Error struct {
+	Kind    Kind
+	Code    string       // стабильный код для клиентов, например book_not_found; не меняется вместе с текстом сообщения
+	Message string       // описание для человека
+	Fields  []FieldError // ошибки по полям для KindValidation
+	Details any          // дополнительные сведения, например текущее состояние записи при KindPreconditionFailed
+	Err     error        // исходная ошибка
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Message + ": " + e.Err.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap запоминает исходную ошибку; она доступна через errors.Is/As, но клиенту не показывается
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// WithField добавляет ошибку поля
+func (e *Error) WithField(field, message string) *Error {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+	return e
+}
+
+// WithDetails прикладывает к ошибке дополнительные сведения для клиента
+func (e *Error) WithDetails(details any) *Error {
+	e.Details = details
+	return e
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func InsufficientStock(code, message string) *Error {
+	return New(KindInsufficientStock, code, message)
+}
+
+func PreconditionFailed(code, message string) *Error {
+	return New(KindPreconditionFailed, code, message)
+}
+
+func PreconditionRequired(code, message string) *Error {
+	return New(KindPreconditionRequired, code, message)
+}
+
+func Unprocessable(code, message string) *Error {
+	return New(KindUnprocessable, code, message)
+}
+
+func PayloadTooLarge(code, message string) *Error {
+	return New(KindPayloadTooLarge, code, message)
+}
+
+// Internal оборачивает непредвиденную ошибку; истечение срока контекста превращается в KindTimeout
+func Internal(err error) *Error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return New(KindTimeout, "timeout", "operation took too long and was cancelled").Wrap(err)
+	}
+	return New(KindInternal, "internal", "Internal Server Error").Wrap(err)
+}
+
+// From приводит любую ошибку к *Error: ошибки каталога возвращаются как есть, остальные считаются внутренними
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// Is сообщает, что в цепочке err есть ошибка каталога вида kind
+func Is(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
+
+// Status - единственное соответствие вида ошибки и HTTP-статуса
+func Status(kind Kind) int {
+	switch kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict, KindInsufficientStock:
+		return http.StatusConflict
+	case KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case KindPayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case KindTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
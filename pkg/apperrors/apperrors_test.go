@@ -0,0 +1,101 @@
+package apperrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestFromKeepsKindThroughWrapping(t *testing.T) {
+	notFound := NotFound("book_not_found", "book not found")
+	wrapped := fmt.Errorf("find book: %w", notFound)
+	if got := From(wrapped); got != notFound {
+		t.Errorf("From(wrapped) = %v, want %v", got, notFound)
+	}
+	if !Is(wrapped, KindNotFound) || Is(wrapped, KindConflict) {
+		t.Errorf("Is(wrapped) does not match kind %s", notFound.Kind)
+	}
+
+	cause := errors.New("connection refused")
+	internal := From(cause)
+	if internal.Kind != KindInternal || !errors.Is(internal, cause) {
+		t.Errorf("From(plain error) = %+v", internal)
+	}
+	if timeout := From(fmt.Errorf("query: %w", context.DeadlineExceeded)); timeout.Kind != KindTimeout {
+		t.Errorf("From(deadline) kind = %s, want %s", timeout.Kind, KindTimeout)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	cases := map[Kind]int{
+		KindValidation:           http.StatusBadRequest,
+		KindUnauthorized:         http.StatusUnauthorized,
+		KindForbidden:            http.StatusForbidden,
+		KindNotFound:             http.StatusNotFound,
+		KindConflict:             http.StatusConflict,
+		KindInsufficientStock:    http.StatusConflict,
+		KindPreconditionFailed:   http.StatusPreconditionFailed,
+		KindPreconditionRequired: http.StatusPreconditionRequired,
+		KindUnprocessable:        http.StatusUnprocessableEntity,
+		KindPayloadTooLarge:      http.StatusRequestEntityTooLarge,
+		KindTimeout:              http.StatusGatewayTimeout,
+		KindInternal:             http.StatusInternalServerError,
+		Kind("unknown"):          http.StatusInternalServerError,
+	}
+	for kind, want := range cases {
+		if got := Status(kind); got != want {
+			t.Errorf("Status(%s) = %d, want %d", kind, got, want)
+		}
+	}
+}
+
+func TestNewProblemHidesInternalCause(t *testing.T) {
+	problem := NewProblem(errors.New("pq: password authentication failed"), "/api/v1/books")
+	body, _ := json.Marshal(problem)
+	if strings.Contains(string(body), "password") {
+		t.Errorf("problem leaks the cause: %s", body)
+	}
+	if problem.Status != http.StatusInternalServerError || problem.Type != "urn:problem-type:internal" || problem.Instance != "/api/v1/books" {
+		t.Errorf("problem = %+v", problem)
+	}
+}
+
+func TestFromBindingReportsFields(t *testing.T) {
+	type contributor struct {
+		Role string `json:"role" validate:"oneof=author translator"`
+	}
+	type request struct {
+		Title        string        `json:"title" validate:"required"`
+		Contributors []contributor `json:"contributors" validate:"dive"`
+	}
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		return name
+	})
+	err := validate.Struct(request{Contributors: []contributor{{Role: "author"}, {Role: "editor"}}})
+
+	appErr := FromBinding(err)
+	want := []FieldError{
+		{Field: "title", Message: "is required"},
+		{Field: "contributors[1].role", Message: "must be one of: author, translator"},
+	}
+	if appErr.Kind != KindValidation || fmt.Sprint(appErr.Fields) != fmt.Sprint(want) {
+		t.Errorf("FromBinding() fields = %v, want %v", appErr.Fields, want)
+	}
+
+	var target struct {
+		Quantity int `json:"quantity"`
+	}
+	appErr = FromBinding(json.Unmarshal([]byte(`{"quantity":"ten"}`), &target))
+	if len(appErr.Fields) != 1 || appErr.Fields[0].Field != "quantity" {
+		t.Errorf("FromBinding(type error) fields = %v", appErr.Fields)
+	}
+}
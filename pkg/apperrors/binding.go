@@ -0,0 +1,76 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromBinding превращает ошибку разбора или проверки тела и параметров запроса в KindValidation с ошибками полей
+func FromBinding(err error) *Error {
+	appErr := Validation("invalid_request", "request is not valid").Wrap(err)
+
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+	var syntaxError *json.SyntaxError
+	var numError *strconv.NumError
+	switch {
+	case errors.As(err, &validationErrors):
+		for _, fieldError := range validationErrors {
+			appErr.WithField(fieldPath(fieldError), fieldMessage(fieldError))
+		}
+	case errors.As(err, &typeError):
+		appErr.WithField(typeError.Field, "must be a "+typeError.Type.String())
+	case errors.As(err, &syntaxError):
+		appErr.Message = "request body is not valid JSON"
+	case errors.As(err, &numError):
+		appErr.Message = "value " + strconv.Quote(numError.Num) + " is not a number"
+	case errors.Is(err, io.EOF):
+		appErr.Message = "request body is empty"
+	default:
+		appErr.Message = err.Error()
+	}
+	return appErr
+}
+
+// fieldPath убирает из пути поля имя корневой структуры: CreateOrUpdateBookRequest.contributors[0].role -> contributors[0].role
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+	return path
+}
+
+func fieldMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return "must be at least " + fieldError.Param()
+	case "max", "lte":
+		return "must be at most " + fieldError.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fieldError.Param(), " ", ", ")
+	case "uuid":
+		return "must be a UUID"
+	case "email":
+		return "must be an email address"
+	case "datetime":
+		if fieldError.Param() == time.DateOnly {
+			return "must be a date in YYYY-MM-DD format"
+		}
+		return "must be a time in " + fieldError.Param() + " layout"
+	case "isbn":
+		return "must be a valid ISBN-10 or ISBN-13"
+	case "bcp47_language_tag":
+		return "must be a BCP 47 language tag, for example ru or en-GB"
+	default:
+		return "failed the " + fieldError.Tag() + " check"
+	}
+}
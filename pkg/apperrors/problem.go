@@ -0,0 +1,52 @@
+package apperrors
+
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix делает поле type ответа URI; значения стабильны и не зависят от адреса сервиса
+const problemTypePrefix = "urn:problem-type:"
+
+// Problem - тело ответа об ошибке по RFC 7807, дополненное кодом ошибки и ошибками полей
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	Details  any          `json:"details,omitempty"`
+}
+
+var titles = map[Kind]string{
+	KindValidation:           "Request is not valid",
+	KindUnauthorized:         "Authentication required",
+	KindForbidden:            "Access denied",
+	KindNotFound:             "Resource not found",
+	KindConflict:             "Conflict with the current state",
+	KindInsufficientStock:    "Insufficient stock",
+	KindPreconditionFailed:   "Resource was modified",
+	KindPreconditionRequired: "Precondition required",
+	KindUnprocessable:        "Request cannot be processed",
+	KindPayloadTooLarge:      "Request body is too large",
+	KindTimeout:              "Operation timed out",
+	KindInternal:             "Internal Server Error",
+}
+
+// NewProblem строит тело ответа для ошибки; instance - путь запроса, в котором она возникла
+func NewProblem(err error, instance string) Problem {
+	appErr := From(err)
+	title, ok := titles[appErr.Kind]
+	if !ok {
+		title = titles[KindInternal]
+	}
+	return Problem{
+		Type:     problemTypePrefix + string(appErr.Kind),
+		Title:    title,
+		Status:   Status(appErr.Kind),
+		Detail:   appErr.Message,
+		Instance: instance,
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+		Details:  appErr.Details,
+	}
+}
@@ -7,12 +7,15 @@ import (
 	"time"
 )
 
+// BaseRepository выполняет запросы через database/sql. Запрос прерывается вместе с контекстом вызывающего,
+// а если задан Timeout, ещё и по его истечении
 type BaseRepository[T any] struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Timeout time.Duration
 }
 
-func (repo *BaseRepository[T]) SelectMultiple(mapRow func(*sql.Rows, *T) error, query string, args ...any) ([]*T, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *BaseRepository[T]) SelectMultiple(ctx context.Context, mapRow func(*sql.Rows, *T) error, query string, args ...any) ([]*T, error) {
+	ctx, cancel := repo.withTimeout(ctx)
 	defer cancel()
 
 	rows, err := repo.DB.QueryContext(ctx, query, args...)
@@ -42,8 +45,8 @@ func (repo *BaseRepository[T]) SelectMultiple(mapRow func(*sql.Rows, *T) error,
 	return list, nil
 }
 
-func (repo *BaseRepository[T]) SelectSingle(mapRow func(*sql.Row, *T) error, query string, args ...any) (*T, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *BaseRepository[T]) SelectSingle(ctx context.Context, mapRow func(*sql.Row, *T) error, query string, args ...any) (*T, error) {
+	ctx, cancel := repo.withTimeout(ctx)
 	defer cancel()
 
 	row := repo.DB.QueryRowContext(ctx, query, args...)
@@ -58,8 +61,8 @@ func (repo *BaseRepository[T]) SelectSingle(mapRow func(*sql.Row, *T) error, que
 	return &t, nil
 }
 
-func (repo *BaseRepository[T]) Insert(query string, args ...any) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *BaseRepository[T]) Insert(ctx context.Context, query string, args ...any) (int, error) {
+	ctx, cancel := repo.withTimeout(ctx)
 	defer cancel()
 
 	var id int
@@ -73,8 +76,8 @@ func (repo *BaseRepository[T]) Insert(query string, args ...any) (int, error) {
 	return id, nil
 }
 
-func (repo *BaseRepository[T]) ExecuteQuery(query string, args ...any) (sql.Result, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (repo *BaseRepository[T]) ExecuteQuery(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := repo.withTimeout(ctx)
 	defer cancel()
 
 	result, err := repo.DB.ExecContext(ctx, query, args...)
@@ -84,3 +87,10 @@ func (repo *BaseRepository[T]) ExecuteQuery(query string, args ...any) (sql.Resu
 
 	return result, nil
 }
+
+func (repo *BaseRepository[T]) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if repo.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, repo.Timeout)
+}
@@ -9,7 +9,10 @@ import (
 )
 
 func NewDatabaseConnection(config *config.Config) *gorm.DB {
-	db, err := gorm.Open(postgres.Open(config.Database.BookDB), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(config.Database.BookDB), &gorm.Config{
+		TranslateError: true,
+		Logger:         newQueryLogger(config.Database.SlowQuery),
+	})
 	if err != nil {
 		log.Fatal("Cannot open database connection", err)
 	}
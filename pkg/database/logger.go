@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gin_main/pkg/logger"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// queryLogger пишет запросы gorm в логгер из контекста запроса, поэтому у записей о SQL тот же request_id,
+// что и у записи о HTTP-запросе. Ошибки запросов пишутся с уровнем debug: чаще всего это ожидаемые
+// нарушения ограничений, а непредвиденные ошибки попадают в лог вместе с запросом через сервисы
+type queryLogger struct {
+	slowQuery time.Duration
+}
+
+func newQueryLogger(slowQuery time.Duration) gormlogger.Interface {
+	return &queryLogger{slowQuery: slowQuery}
+}
+
+// LogMode не используется: уровень задаётся логгером приложения
+func (l *queryLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...any) {
+	logger.FromContext(ctx).Info().Msgf(msg, args...)
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...any) {
+	logger.FromContext(ctx).Warn().Msgf(msg, args...)
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...any) {
+	logger.FromContext(ctx).Error().Msgf(msg, args...)
+}
+
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	log := logger.FromContext(ctx)
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.Debug().Err(err).Str("sql", sql).Int64("rows", rows).Dur("elapsed", elapsed).Msg("Query failed")
+	case elapsed > l.slowQuery:
+		sql, rows := fc()
+		log.Warn().Str("sql", sql).Int64("rows", rows).Dur("elapsed", elapsed).Msg("Slow query")
+	case log.GetLevel() <= zerolog.TraceLevel:
+		sql, rows := fc()
+		log.Trace().Str("sql", sql).Int64("rows", rows).Dur("elapsed", elapsed).Msg("Query")
+	}
+}
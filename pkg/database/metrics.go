@@ -0,0 +1,85 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "metrics:query_start"
+
+// queryMetrics - плагин gorm, замеряющий длительность запросов и считающий их ошибки по операции и таблице
+type queryMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewMetrics подключает к db замер запросов и возвращает метрики запросов и пула соединений для регистрации
+func NewMetrics(db *gorm.DB) ([]prometheus.Collector, error) {
+	metrics := &queryMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Database query latency by gorm operation and table.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"operation", "table"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_query_errors_total",
+			Help: "Failed database queries by gorm operation and table; record not found is not counted.",
+		}, []string{"operation", "table"}),
+	}
+	if err := db.Use(metrics); err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return []prometheus.Collector{metrics.duration, metrics.errors, collectors.NewDBStatsCollector(sqlDB, "book")}, nil
+}
+
+func (m *queryMetrics) Name() string {
+	return "metrics"
+}
+
+// Initialize оборачивает каждый обработчик gorm парой колбэков: до него запоминается время начала, после - замер
+func (m *queryMetrics) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("metrics:start_create", startQuery),
+		callbacks.Create().After("gorm:create").Register("metrics:observe_create", m.observe("create")),
+		callbacks.Query().Before("gorm:query").Register("metrics:start_query", startQuery),
+		callbacks.Query().After("gorm:query").Register("metrics:observe_query", m.observe("query")),
+		callbacks.Update().Before("gorm:update").Register("metrics:start_update", startQuery),
+		callbacks.Update().After("gorm:update").Register("metrics:observe_update", m.observe("update")),
+		callbacks.Delete().Before("gorm:delete").Register("metrics:start_delete", startQuery),
+		callbacks.Delete().After("gorm:delete").Register("metrics:observe_delete", m.observe("delete")),
+		callbacks.Row().Before("gorm:row").Register("metrics:start_row", startQuery),
+		callbacks.Row().After("gorm:row").Register("metrics:observe_row", m.observe("row")),
+		callbacks.Raw().Before("gorm:raw").Register("metrics:start_raw", startQuery),
+		callbacks.Raw().After("gorm:raw").Register("metrics:observe_raw", m.observe("raw")),
+	)
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func (m *queryMetrics) observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		m.duration.WithLabelValues(operation, table).Observe(time.Since(value.(time.Time)).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			m.errors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
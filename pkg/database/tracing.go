@@ -0,0 +1,77 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const querySpanKey = "tracing:query_span"
+
+// queryTracing - плагин gorm, открывающий на каждый SQL-запрос дочерний спан от контекста, переданного в WithContext
+type queryTracing struct {
+	tracer trace.Tracer
+}
+
+// NewTracing подключает к db трассировку запросов; без настроенного экспортёра спаны никуда не отправляются
+func NewTracing(db *gorm.DB) error {
+	return db.Use(&queryTracing{tracer: otel.Tracer("gin_main/pkg/database")})
+}
+
+func (t *queryTracing) Name() string {
+	return "tracing"
+}
+
+// Initialize устроен так же, как у queryMetrics: до обработчика gorm спан открывается, после - закрывается
+func (t *queryTracing) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:start_create", t.start("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:end_create", endSpan),
+		callbacks.Query().Before("gorm:query").Register("tracing:start_query", t.start("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:end_query", endSpan),
+		callbacks.Update().Before("gorm:update").Register("tracing:start_update", t.start("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:end_update", endSpan),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:start_delete", t.start("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:end_delete", endSpan),
+		callbacks.Row().Before("gorm:row").Register("tracing:start_row", t.start("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:end_row", endSpan),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:start_raw", t.start("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:end_raw", endSpan),
+	)
+}
+
+func (t *queryTracing) start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		attributes := []attribute.KeyValue{semconv.DBSystemNamePostgreSQL, semconv.DBOperationName(operation)}
+		// у Raw и Row таблица обычно неизвестна
+		if db.Statement.Table != "" {
+			attributes = append(attributes, semconv.DBCollectionName(db.Statement.Table))
+		}
+		_, span := t.tracer.Start(db.Statement.Context, "db."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attributes...),
+		)
+		db.InstanceSet(querySpanKey, span)
+	}
+}
+
+// endSpan дописывает текст запроса: до обработчика gorm он ещё не собран
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(querySpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+	span.SetAttributes(semconv.DBQueryText(db.Statement.SQL.String()))
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,107 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	healthOK   = "ok"
+	healthFail = "fail"
+)
+
+// HealthCheck проверяет зависимость сервиса; ошибка делает /readyz неготовым
+type HealthCheck func(ctx context.Context) error
+
+type healthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+type checkResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+var errShuttingDown = errors.New("server is shutting down")
+
+// AddReadinessCheck добавляет проверку в /readyz; результат отдаётся под именем name
+func (s *Server) AddReadinessCheck(name string, check HealthCheck) {
+	s.checks[name] = check
+}
+
+// live отвечает, пока процесс способен обрабатывать запросы, и не трогает зависимости:
+// иначе сбой базы приводил бы к перезапуску всех инстансов
+func (s *Server) live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, healthResponse{Status: healthOK})
+}
+
+// ready выполняет все проверки параллельно, каждую со сроком timeouts.readiness.
+// После SIGTERM отвечает 503 сразу, не дожидаясь остановки зависимостей
+func (s *Server) ready(ctx *gin.Context) {
+	checks := map[string]HealthCheck{
+		"server":  s.checkDraining,
+		"workers": s.checkWorkers,
+	}
+	for name, check := range s.checks {
+		checks[name] = check
+	}
+
+	response := healthResponse{Status: healthOK, Checks: make(map[string]checkResult, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), s.config.Timeouts.Readiness)
+			defer cancel()
+			result := checkResult{Status: healthOK}
+			if err := check(checkCtx); err != nil {
+				result = checkResult{Status: healthFail, Error: err.Error()}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			response.Checks[name] = result
+			if result.Status == healthFail {
+				response.Status = healthFail
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if response.Status == healthFail {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, response)
+}
+
+func (s *Server) checkDraining(context.Context) error {
+	if s.draining.Load() {
+		return errShuttingDown
+	}
+	return nil
+}
+
+// checkWorkers не даёт считать готовым инстанс, у которого завершилась фоновая задача
+func (s *Server) checkWorkers(context.Context) error {
+	var stopped []string
+	for name, running := range s.workerRunning {
+		if !running.Load() {
+			stopped = append(stopped, name)
+		}
+	}
+	if len(stopped) > 0 {
+		slices.Sort(stopped)
+		return fmt.Errorf("workers not running: %s", strings.Join(stopped, ", "))
+	}
+	return nil
+}
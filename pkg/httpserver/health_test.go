@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_main/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+func newTestServer() (*Server, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	log := zerolog.Nop()
+	cfg := &config.Config{}
+	cfg.Timeouts.Readiness = 50 * time.Millisecond
+	engine := gin.New()
+	return NewServer(&log, engine, cfg), engine
+}
+
+func probe(t *testing.T, engine *gin.Engine, path string) (int, healthResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var response healthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s body %q: %v", path, rec.Body.String(), err)
+	}
+	return rec.Code, response
+}
+
+func TestReadyz(t *testing.T) {
+	server, engine := newTestServer()
+	server.AddReadinessCheck("database", func(context.Context) error { return nil })
+	server.AddWorker("sweeper", func(context.Context) {})
+	server.workerRunning["sweeper"].Store(true)
+
+	if code, response := probe(t, engine, "/readyz"); code != http.StatusOK || response.Status != healthOK || len(response.Checks) != 3 {
+		t.Fatalf("healthy /readyz = %d %+v, want 200 with server, workers and database checks", code, response)
+	}
+
+	server.AddReadinessCheck("migrations", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	server.workerRunning["sweeper"].Store(false)
+	code, response := probe(t, engine, "/readyz")
+	if code != http.StatusServiceUnavailable || response.Status != healthFail {
+		t.Fatalf("/readyz = %d %+v, want 503", code, response)
+	}
+	if got := response.Checks["migrations"]; got.Status != healthFail || got.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("migrations check = %+v, want failure by timeout", got)
+	}
+	if got := response.Checks["workers"]; got.Error != "workers not running: sweeper" {
+		t.Errorf("workers check = %+v, want the stopped worker named", got)
+	}
+	if got := response.Checks["database"]; got.Status != healthOK {
+		t.Errorf("database check = %+v, want ok", got)
+	}
+}
+
+func TestReadyzFailsWhileDraining(t *testing.T) {
+	server, engine := newTestServer()
+	server.draining.Store(true)
+
+	code, response := probe(t, engine, "/readyz")
+	if code != http.StatusServiceUnavailable || response.Checks["server"].Error != errShuttingDown.Error() {
+		t.Errorf("draining /readyz = %d %+v, want 503 from the server check", code, response)
+	}
+	if code, _ := probe(t, engine, "/healthz"); code != http.StatusOK {
+		t.Errorf("draining /healthz = %d, want 200: liveness must not restart a draining instance", code)
+	}
+}
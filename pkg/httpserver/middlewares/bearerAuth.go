@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"gin_main/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const identityKey = "identity"
+
+// Identity описывает вызывающего пользователя, извлечённого из access токена
+type Identity struct {
+	UserID uuid.UUID
+	Login  string
+	Role   string
+}
+
+type Authenticator interface {
+	// Authenticate проверяет access токен и то, что пользователь всё ещё может работать;
+	// ошибки каталога apperrors отдаются клиенту как есть, остальные считаются недействительным токеном
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// BearerAuthMiddleware проверяет заголовок Authorization и кладёт Identity в контекст gin
+func BearerAuthMiddleware(authenticator Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scheme, token, found := strings.Cut(ctx.GetHeader("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			ctx.Header("WWW-Authenticate", "Bearer")
+			AbortWithProblem(ctx, apperrors.Unauthorized("bearer_token_required", "bearer token required"))
+			return
+		}
+		identity, err := authenticator.Authenticate(ctx.Request.Context(), token)
+		if err != nil {
+			var inError *apperrors.Error
+			if !errors.As(err, &inError) {
+				inError = apperrors.Unauthorized("invalid_token", "invalid or expired token").Wrap(err)
+			}
+			if inError.Kind == apperrors.KindUnauthorized {
+				ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			AbortWithProblem(ctx, inError)
+			return
+		}
+		ctx.Set(identityKey, identity)
+		ctx.Next()
+	}
+}
+
+// RequireRole пропускает запрос, только если роль вызывающего входит в список
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity, ok := GetIdentity(ctx)
+		if !ok {
+			AbortWithProblem(ctx, apperrors.Unauthorized("authentication_required", "authentication required"))
+			return
+		}
+		if !slices.Contains(roles, identity.Role) {
+			AbortWithProblem(ctx, apperrors.Forbidden("insufficient_role", "insufficient role"))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func GetIdentity(ctx *gin.Context) (*Identity, bool) {
+	value, exists := ctx.Get(identityKey)
+	if !exists {
+		return nil, false
+	}
+	identity, ok := value.(*Identity)
+	return identity, ok
+}
@@ -0,0 +1,128 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// IdempotencyMiddleware возвращает сохранённый ответ на повтор запроса с тем же заголовком Idempotency-Key
+// вместо повторного выполнения. Запросы без заголовка проходят как есть. Тело запроса с ключом читается
+// в память и сохраняется, поэтому оно не может быть больше maxBodySize байт
+func IdempotencyMiddleware(store idempotency.Store, ttl time.Duration, maxBodySize int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			AbortWithProblem(ctx, apperrors.Validation("idempotency_key_too_long", "idempotency key is too long").
+				WithField(IdempotencyKeyHeader, "must be at most 255 characters"))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				AbortWithProblem(ctx, apperrors.PayloadTooLarge("body_too_large", fmt.Sprintf("request body must be at most %d bytes", maxBodySize)))
+				return
+			}
+			AbortWithProblem(ctx, apperrors.Validation("unreadable_body", "cannot read request body").Wrap(err))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotencyScope(ctx)
+		requestHash := hashRequest(ctx.Request.Method, ctx.Request.URL.Path, body)
+		record, created, err := store.Begin(ctx.Request.Context(), scope, key, requestHash, ttl)
+		if err != nil {
+			AbortWithProblem(ctx, apperrors.Internal(err))
+			return
+		}
+		if !created {
+			switch {
+			case record.RequestHash != requestHash:
+				AbortWithProblem(ctx, apperrors.Unprocessable("idempotency_key_reused", "idempotency key was already used with a different request"))
+			case !record.Completed:
+				AbortWithProblem(ctx, apperrors.Conflict("idempotency_key_in_progress", "request with this idempotency key is still in progress"))
+			default:
+				ctx.Header(idempotencyReplayedHeader, "true")
+				ctx.Data(record.StatusCode, record.ContentType, record.Body)
+				ctx.Abort()
+			}
+			return
+		}
+
+		// результат сохраняется и после обрыва соединения клиентом, иначе ключ навсегда останется "в обработке"
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+		defer func() {
+			// паника обработчика тоже освобождает ключ: иначе до истечения ttl все повторы получали бы 409.
+			// Саму панику дальше обрабатывает recovery-middleware
+			if recovered := recover(); recovered != nil {
+				_ = store.Release(storeCtx, scope, key)
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		if ctx.Writer.Status() >= http.StatusInternalServerError {
+			_ = store.Release(storeCtx, scope, key)
+			return
+		}
+		if err := store.Complete(storeCtx, scope, key, ctx.Writer.Status(), ctx.Writer.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			_ = ctx.Error(err)
+		}
+	}
+}
+
+// idempotencyScope ограничивает ключ пользователем и маршрутом, чтобы ключи разных клиентов не пересекались
+func idempotencyScope(ctx *gin.Context) string {
+	scope := ctx.Request.Method + " " + ctx.FullPath()
+	if identity, ok := GetIdentity(ctx); ok {
+		scope = identity.UserID.String() + " " + scope
+	}
+	return scope
+}
+
+func hashRequest(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + "\n" + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
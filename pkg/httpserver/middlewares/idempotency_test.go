@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin_main/pkg/apperrors"
+	"gin_main/pkg/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryStore хранит ключи идемпотентности в памяти теста
+type memoryStore struct {
+	records  map[string]*idempotency.Record
+	released int
+}
+
+func (s *memoryStore) Begin(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (*idempotency.Record, bool, error) {
+	if record, exists := s.records[scope+key]; exists {
+		return record, false, nil
+	}
+	s.records[scope+key] = &idempotency.Record{RequestHash: requestHash}
+	return nil, true, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	record := s.records[scope+key]
+	record.Completed, record.StatusCode, record.ContentType, record.Body = true, statusCode, contentType, body
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, scope, key string) error {
+	s.released++
+	delete(s.records, scope+key)
+	return nil
+}
+
+func TestIdempotencyMiddlewareReleasesKeyOnPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &memoryStore{records: map[string]*idempotency.Record{}}
+	calls := 0
+	engine := gin.New()
+	engine.Use(gin.CustomRecovery(func(ctx *gin.Context, recovered any) {
+		AbortWithProblem(ctx, apperrors.Internal(fmt.Errorf("panic: %v", recovered)))
+	}))
+	engine.POST("/orders", IdempotencyMiddleware(store, time.Hour, 1<<10), func(ctx *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		ctx.String(http.StatusCreated, "created")
+	})
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"quantity":1}`))
+		req.Header.Set(IdempotencyKeyHeader, "retry-1")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("panicking request: status = %d, want 500 from recovery", rec.Code)
+	}
+	if store.released != 1 || len(store.records) != 0 {
+		t.Fatalf("released %d keys, %d left, want the key released", store.released, len(store.records))
+	}
+	if rec := send(); rec.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("retry: status = %d after %d calls, want the handler to run again", rec.Code, calls)
+	}
+}
+
+func TestIdempotencyMiddlewareLimitsBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &memoryStore{records: map[string]*idempotency.Record{}}
+	calls := 0
+	engine := gin.New()
+	engine.POST("/orders", IdempotencyMiddleware(store, time.Hour, 16), func(ctx *gin.Context) {
+		calls++
+		ctx.String(http.StatusCreated, "created")
+	})
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "key-"+body)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(`{"quantity":100000}`)
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "body_too_large") {
+		t.Errorf("oversized body: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if calls != 0 || len(store.records) != 0 {
+		t.Errorf("oversized body: %d handler calls, %d stored keys, want none", calls, len(store.records))
+	}
+	if rec = send(`{"quantity":1}`); rec.Code != http.StatusCreated {
+		t.Errorf("body within limit: status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gin_main/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	RequestIDHeader    = "X-Request-ID"
+	requestIDKey       = "requestID"
+	maxRequestIDLength = 128
+)
+
+// LogContextMiddleware назначает запросу X-Request-ID (или принимает его от клиента), кладёт в контекст запроса
+// логгер с этим идентификатором и после обработки пишет одну запись о запросе.
+// Подключается сразу после TracingMiddleware (тогда в записи попадают trace_id и span_id) и до остальных middleware,
+// чтобы в лог попадали и запросы, отклонённые проверкой токена
+func LogContextMiddleware(log *zerolog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		ctx.Set(requestIDKey, requestID)
+		ctx.Header(RequestIDHeader, requestID)
+
+		logContext := log.With().Str("request_id", requestID)
+		if traceID, spanID, ok := traceFields(ctx); ok {
+			logContext = logContext.Str("trace_id", traceID).Str("span_id", spanID)
+		}
+		requestLogger := logContext.Logger()
+		ctx.Request = ctx.Request.WithContext(logger.WithLogger(ctx.Request.Context(), &requestLogger))
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		var event *zerolog.Event
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = requestLogger.Error()
+		case status >= http.StatusBadRequest:
+			event = requestLogger.Warn()
+		default:
+			event = requestLogger.Info()
+		}
+		event = event.
+			Str("method", ctx.Request.Method).
+			Str("route", ctx.FullPath()).
+			Str("path", ctx.Request.URL.Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Int("bytes", max(ctx.Writer.Size(), 0)).
+			Str("client_ip", ctx.ClientIP())
+		if identity, ok := GetIdentity(ctx); ok {
+			event = event.Str("user_id", identity.UserID.String())
+		}
+		// сюда попадают причины внутренних ошибок: клиенту они не показываются, см. AbortWithProblem
+		if len(ctx.Errors) > 0 {
+			errs := make([]error, 0, len(ctx.Errors))
+			for _, err := range ctx.Errors {
+				errs = append(errs, err.Err)
+			}
+			event = event.Err(errors.Join(errs...))
+		}
+		event.Msg("Request handled")
+	}
+}
+
+// GetRequestID возвращает идентификатор запроса, назначенный LogContextMiddleware
+func GetRequestID(ctx *gin.Context) string {
+	return ctx.GetString(requestIDKey)
+}
+
+// validRequestID отбрасывает пустые, слишком длинные и непечатаемые идентификаторы, чтобы клиент не мог испортить лог
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
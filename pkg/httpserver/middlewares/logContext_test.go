@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin_main/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestLogContextMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	log := zerolog.New(&out)
+	userID := uuid.New()
+
+	engine := gin.New()
+	engine.Use(LogContextMiddleware(&log))
+	engine.GET("/books/:id", func(ctx *gin.Context) {
+		ctx.Set(identityKey, &Identity{UserID: userID})
+		logger.FromContext(ctx.Request.Context()).Info().Msg("from handler")
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/books/42", nil)
+	req.Header.Set(RequestIDHeader, "client-id-1")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "client-id-1" {
+		t.Errorf("response %s = %q, want the client's id", RequestIDHeader, got)
+	}
+	decoder := json.NewDecoder(&out)
+	var handlerEntry, requestEntry map[string]any
+	if err := decoder.Decode(&handlerEntry); err != nil {
+		t.Fatalf("handler entry: %v", err)
+	}
+	if err := decoder.Decode(&requestEntry); err != nil {
+		t.Fatalf("request entry: %v", err)
+	}
+	if handlerEntry["request_id"] != "client-id-1" {
+		t.Errorf("handler entry = %v, want request_id from the header", handlerEntry)
+	}
+	want := map[string]any{
+		"request_id": "client-id-1",
+		"method":     "GET",
+		"route":      "/books/:id",
+		"status":     float64(http.StatusOK),
+		"bytes":      float64(2),
+		"user_id":    userID.String(),
+	}
+	for key, value := range want {
+		if requestEntry[key] != value {
+			t.Errorf("request entry %s = %v, want %v", key, requestEntry[key], value)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/books/42", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if _, err := uuid.Parse(rec.Header().Get(RequestIDHeader)); err != nil {
+		t.Errorf("invalid client id was not replaced: %q", rec.Header().Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute - метка для запросов без маршрута: подставлять сам путь нельзя, иначе число рядов метрики не ограничено
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware считает запросы и их длительность по шаблону маршрута gin (/api/v1/books/:id) и статусу ответа
+func MetricsMiddleware(registerer prometheus.Registerer) gin.HandlerFunc {
+	labels := []string{"method", "route", "status"}
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route template and status.",
+	}, labels)
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route template and status.",
+		Buckets: prometheus.DefBuckets,
+	}, labels)
+	registerer.MustRegister(requests, duration)
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+		requests.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		duration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	engine := gin.New()
+	engine.Use(MetricsMiddleware(registry))
+	engine.GET("/books/:id", func(ctx *gin.Context) { ctx.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/books/1", "/books/2", "/missing/3"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	requests, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := map[string]float64{}
+	for _, family := range requests {
+		if family.GetName() != "http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			series[labels["route"]+" "+labels["status"]] = metric.GetCounter().GetValue()
+		}
+	}
+	if series["/books/:id 204"] != 2 || series[unmatchedRoute+" 404"] != 1 || len(series) != 2 {
+		t.Errorf("http_requests_total = %v", series)
+	}
+	if count := testutil.CollectAndCount(registry, "http_request_duration_seconds"); count != 2 {
+		t.Errorf("http_request_duration_seconds series = %d, want 2", count)
+	}
+}
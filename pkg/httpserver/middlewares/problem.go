@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"encoding/json"
+
+	"gin_main/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AbortWithProblem прерывает запрос ответом application/problem+json; статус выбирается по виду ошибки.
+// Внутренние ошибки добавляются в ctx.Errors, чтобы их причину можно было записать в лог
+func AbortWithProblem(ctx *gin.Context, err error) {
+	appErr := apperrors.From(err)
+	if appErr.Kind == apperrors.KindInternal || appErr.Kind == apperrors.KindTimeout {
+		_ = ctx.Error(err)
+	}
+	problem := apperrors.NewProblem(appErr, ctx.Request.URL.Path)
+	body, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		_ = ctx.Error(marshalErr)
+		ctx.AbortWithStatus(problem.Status)
+		return
+	}
+	ctx.Data(problem.Status, apperrors.ProblemContentType, body)
+	ctx.Abort()
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"gin_main/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryMiddleware отвечает на панику обработчика ошибкой 500 в формате problem+json; стек паники
+// попадает в ctx.Errors и пишется в лог запроса. http.ErrAbortHandler пробрасывается дальше:
+// net/http сбрасывает соединение, так обработчик обрывает уже начатый потоковый ответ
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(ctx *gin.Context, recovered any) {
+		if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+			panic(recovered)
+		}
+		AbortWithProblem(ctx, apperrors.Internal(fmt.Errorf("panic: %v\n%s", recovered, debug.Stack())))
+	})
+}
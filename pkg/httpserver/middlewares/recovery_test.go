@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin_main/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(RecoveryMiddleware())
+	engine.GET("/panic", func(ctx *gin.Context) { panic("boom") })
+	engine.GET("/abort", func(ctx *gin.Context) { panic(http.ErrAbortHandler) })
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get("Content-Type") != apperrors.ProblemContentType {
+		t.Errorf("panic: status = %d, content type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("abort: recovered = %v, want http.ErrAbortHandler passed to net/http", recovered)
+		}
+	}()
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware ограничивает время обработки запроса: по истечении срока контекст запроса отменяется,
+// и вместе с ним прерываются все обращения к базе. Маршрутам из overrides (ключ - шаблон пути gin,
+// например "/api/v1/books/export") назначается собственный срок
+func TimeoutMiddleware(timeout time.Duration, overrides map[string]time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		limit := timeout
+		if override, ok := overrides[ctx.FullPath()]; ok {
+			limit = override
+		}
+		requestCtx, cancel := context.WithTimeout(ctx.Request.Context(), limit)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+		ctx.Next()
+	}
+}
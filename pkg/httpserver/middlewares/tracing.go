@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "gin_main/pkg/httpserver"
+
+// TracingMiddleware открывает серверный спан на запрос. Если клиент прислал traceparent, спан продолжает его трассу.
+// Контекст со спаном кладётся в запрос, поэтому сервисы и SQL-запросы становятся его дочерними спанами
+func TracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer(tracerName)
+	return func(ctx *gin.Context) {
+		parent := otel.GetTextMapPropagator().Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		spanCtx, span := tracer.Start(parent, ctx.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(ctx.Request.URL.Path),
+				semconv.ClientAddress(ctx.ClientIP()),
+			),
+		)
+		defer span.End()
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if identity, ok := GetIdentity(ctx); ok {
+			span.SetAttributes(semconv.UserID(identity.UserID.String()))
+		}
+		for _, err := range ctx.Errors {
+			span.RecordError(err.Err)
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// traceFields возвращает идентификаторы трассы и спана из контекста; ok = false, если спана нет
+func traceFields(ctx *gin.Context) (traceID, spanID string, ok bool) {
+	spanContext := trace.SpanContextFromContext(ctx.Request.Context())
+	if !spanContext.IsValid() {
+		return "", "", false
+	}
+	return spanContext.TraceID().String(), spanContext.SpanID().String(), true
+}
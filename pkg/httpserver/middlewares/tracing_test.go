@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTracingMiddlewareContinuesTraceparent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+	var out bytes.Buffer
+	log := zerolog.New(&out)
+
+	engine := gin.New()
+	engine.Use(TracingMiddleware(), LogContextMiddleware(&log))
+	engine.GET("/books/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/books/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("ended spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /books/:id" {
+		t.Errorf("span name = %q, want the route template", span.Name())
+	}
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want the one from traceparent", got)
+	}
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" || !span.Parent().IsRemote() {
+		t.Errorf("parent = %s (remote %v), want the caller's span", got, span.Parent().IsRemote())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("span status = %v, want Error for 5xx", span.Status())
+	}
+
+	var entry map[string]any
+	if err := json.NewDecoder(&out).Decode(&entry); err != nil {
+		t.Fatalf("request entry: %v", err)
+	}
+	if entry["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || entry["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("request entry = %v, want trace_id and span_id of the server span", entry)
+	}
+}
@@ -1,6 +1,8 @@
 package router
 
 import (
+	"gin_main/pkg/httpserver/middlewares"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,7 +11,15 @@ type HandlerInterface interface {
 }
 
 func RegisterPublicEndpoints(router *gin.Engine, handlers ...HandlerInterface) {
-	api := router.Group("/api")
+	api := router.Group("/api/v1")
+	for _, handler := range handlers {
+		handler.RegisterRoutes(api)
+	}
+}
+
+// RegisterProtectedEndpoints регистрирует маршруты, доступные только с валидным bearer токеном
+func RegisterProtectedEndpoints(router *gin.Engine, authenticator middlewares.Authenticator, handlers ...HandlerInterface) {
+	api := router.Group("/api/v1", middlewares.BearerAuthMiddleware(authenticator))
 	for _, handler := range handlers {
 		handler.RegisterRoutes(api)
 	}
@@ -8,21 +8,73 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"gin_main/pkg/httpserver/middlewares"
+
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 )
 
+// Worker фоновая задача, работающая, пока не отменён ctx
+type Worker func(ctx context.Context)
+
 type Server struct {
-	logger *zerolog.Logger
-	router *gin.Engine
-	config *config.Config
+	logger        *zerolog.Logger
+	router        *gin.Engine
+	config        *config.Config
+	workers       map[string]Worker
+	workerRunning map[string]*atomic.Bool
+	checks        map[string]HealthCheck
+	draining      atomic.Bool
+	registry      *prometheus.Registry
 }
 
+// NewServer создаёт сервер и сразу подключает сбор метрик HTTP и маршруты /metrics, /healthz и /readyz,
+// поэтому метрики считаются для всех маршрутов, зарегистрированных после, а пробы не попадают в лог запросов
 func NewServer(logger *zerolog.Logger, router *gin.Engine, config *config.Config) *Server {
-	return &Server{logger: logger, router: router, config: config}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	server := &Server{
+		logger:        logger,
+		router:        router,
+		config:        config,
+		workers:       make(map[string]Worker),
+		workerRunning: make(map[string]*atomic.Bool),
+		checks:        make(map[string]HealthCheck),
+		registry:      registry,
+	}
+	router.Use(middlewares.MetricsMiddleware(registry))
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})))
+	router.GET("/healthz", server.live)
+	router.GET("/readyz", server.ready)
+	return server
+}
+
+// AddMetrics регистрирует метрики, которые отдаёт /metrics
+func (s *Server) AddMetrics(metrics ...prometheus.Collector) {
+	s.registry.MustRegister(metrics...)
+}
+
+// AddMiddleware подключает middleware ко всем маршрутам; действует только на маршруты, зарегистрированные после вызова
+func (s *Server) AddMiddleware(middleware gin.HandlerFunc) {
+	s.router.Use(middleware)
+}
+
+func (s *Server) GetLogger() *zerolog.Logger {
+	return s.logger
+}
+
+// AddWorker регистрирует фоновую задачу, которая запускается вместе с сервером и останавливается при его остановке
+func (s *Server) AddWorker(name string, worker Worker) {
+	s.workers[name] = worker
+	s.workerRunning[name] = new(atomic.Bool)
 }
 
 func (s *Server) Serve() {
@@ -41,9 +93,28 @@ func (s *Server) Serve() {
 		}
 	}()
 
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workersDone sync.WaitGroup
+	for name, worker := range s.workers {
+		workersDone.Add(1)
+		running := s.workerRunning[name]
+		running.Store(true)
+		go func() {
+			defer workersDone.Done()
+			defer running.Store(false)
+			s.logger.Info().Str("worker", name).Msg("Worker started")
+			worker(workersCtx)
+			s.logger.Info().Str("worker", name).Msg("Worker stopped")
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM) //INF: запихивает ожидаемые перечисленные сигналы в канал quit
 	<-quit
+	// /readyz начинает отвечать 503, а запросы ещё обслуживаются, пока балансировщик не снимет инстанс
+	s.draining.Store(true)
+	s.logger.Info().Dur("drain_delay", s.config.Server.DrainDelay).Msg("Draining before shutdown")
+	time.Sleep(s.config.Server.DrainDelay)
 	s.logger.Info().Msg("Shutting down server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -51,6 +122,8 @@ func (s *Server) Serve() {
 	if err := srv.Shutdown(ctx); err != nil {
 		s.logger.Fatal().Err(err).Msg("Server shutdown")
 	}
+	stopWorkers()
+	workersDone.Wait()
 
 	<-ctx.Done()
 	s.logger.Info().Msg("Server shutdown timeout of 5 seconds")
@@ -0,0 +1,26 @@
+// Package idempotency описывает хранилище ключей Idempotency-Key: его использует middleware HTTP-слоя,
+// а реализует репозиторий, поэтому ни один из слоёв не зависит от другого
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Record сохранённый результат запроса с ключом идемпотентности
+type Record struct {
+	RequestHash string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type Store interface {
+	// Begin резервирует ключ; если ключ уже есть, возвращает существующую запись и false
+	Begin(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (*Record, bool, error)
+	// Complete сохраняет ответ для последующих повторов
+	Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error
+	// Release освобождает ключ, если запрос завершился ошибкой сервера или паникой и может быть повторён
+	Release(ctx context.Context, scope, key string) error
+}
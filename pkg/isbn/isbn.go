@@ -0,0 +1,116 @@
+// Package isbn проверяет и нормализует ISBN-10, ISBN-13 и считанные сканером штрихкоды EAN-13
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidLength   = errors.New("isbn must have 10 or 13 digits")
+	ErrInvalidChecksum = errors.New("isbn checksum does not match")
+	ErrInvalidPrefix   = errors.New("isbn-13 must start with 978 or 979")
+	ErrInvalidAddOn    = errors.New("barcode add-on must have 2 or 5 digits")
+)
+
+// Normalize приводит ISBN к 13 цифрам без разделителей. Принимает дефисы и пробелы,
+// ISBN-10 с контрольным X и ISBN-13; ценовое дополнение штрихкода не допускается
+func Normalize(raw string) (string, error) {
+	code := clean(raw)
+	switch len(code) {
+	case 10:
+		if !validISBN10(code) {
+			return "", ErrInvalidChecksum
+		}
+		return convertISBN10(code), nil
+	case 13:
+		return validISBN13(code)
+	default:
+		return "", ErrInvalidLength
+	}
+}
+
+// NormalizeScanned - Normalize для кода со сканера: EAN-13 может идти слитно с 2- или 5-значным
+// ценовым дополнением, которое печатается рядом со штрихкодом. Дополнение отбрасывается,
+// но должно состоять из цифр
+func NormalizeScanned(raw string) (string, error) {
+	code := clean(raw)
+	switch len(code) {
+	case 15, 18:
+		if strings.ContainsRune(code[13:], 'X') {
+			return "", ErrInvalidAddOn
+		}
+		return validISBN13(code[:13])
+	default:
+		return Normalize(raw)
+	}
+}
+
+// Valid сообщает, является ли строка корректным ISBN-10 или ISBN-13
+func Valid(raw string) bool {
+	_, err := Normalize(raw)
+	return err == nil
+}
+
+// clean оставляет цифры и X; управляющие символы сканера (CR, LF, префиксы AIM вида "]E0") отбрасываются
+func clean(raw string) string {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "]E0")
+	var b strings.Builder
+	for _, r := range strings.ToUpper(raw) {
+		switch {
+		case r >= '0' && r <= '9', r == 'X':
+			b.WriteRune(r)
+		case r == '-' || r == ' ' || r < ' ':
+		default:
+			return ""
+		}
+	}
+	return b.String()
+}
+
+func validISBN13(code string) (string, error) {
+	if strings.ContainsRune(code, 'X') {
+		return "", ErrInvalidChecksum
+	}
+	if !strings.HasPrefix(code, "978") && !strings.HasPrefix(code, "979") {
+		return "", ErrInvalidPrefix
+	}
+	if checkDigit13(code[:12]) != code[12] {
+		return "", ErrInvalidChecksum
+	}
+	return code, nil
+}
+
+func validISBN10(code string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var digit int
+		switch {
+		case code[i] == 'X' && i == 9:
+			digit = 10
+		case code[i] >= '0' && code[i] <= '9':
+			digit = int(code[i] - '0')
+		default:
+			return false
+		}
+		sum += digit * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+func convertISBN10(code string) string {
+	prefixed := "978" + code[:9]
+	return prefixed + string(checkDigit13(prefixed))
+}
+
+func checkDigit13(first12 string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(first12[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
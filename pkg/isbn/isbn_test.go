@@ -0,0 +1,58 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+		err  error
+	}{
+		{"978-5-17-090630-7", "9785170906307", nil},
+		{"0-306-40615-2", "9780306406157", nil},
+		{"080442957X", "9780804429573", nil},
+		{"0-8044-2957-x", "9780804429573", nil},
+		{"9780306406157\r\n", "9780306406157", nil},
+		{"]E09780306406157", "9780306406157", nil},
+		{"978030640615751299", "", ErrInvalidLength},
+		{"978030640615712", "", ErrInvalidLength},
+		{"9780306406158", "", ErrInvalidChecksum},
+		{"0-306-40615-3", "", ErrInvalidChecksum},
+		{"4006381333931", "", ErrInvalidPrefix},
+		{"12345", "", ErrInvalidLength},
+		{"97803064061X7", "", ErrInvalidChecksum},
+		{"isbn 9780306406157", "", ErrInvalidLength},
+	}
+	for _, c := range cases {
+		got, err := Normalize(c.raw)
+		if got != c.want || !errors.Is(err, c.err) {
+			t.Errorf("Normalize(%q) = %q, %v; want %q, %v", c.raw, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestNormalizeScanned(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+		err  error
+	}{
+		{"978030640615751299", "9780306406157", nil},
+		{"978030640615712", "9780306406157", nil},
+		{"]E09780306406157\r\n", "9780306406157", nil},
+		{"0-306-40615-2", "9780306406157", nil},
+		{"9780306406157X1", "", ErrInvalidAddOn},
+		{"97803064061575129X", "", ErrInvalidAddOn},
+		{"978030640615812", "", ErrInvalidChecksum},
+		{"9780306406157123", "", ErrInvalidLength},
+	}
+	for _, c := range cases {
+		got, err := NormalizeScanned(c.raw)
+		if got != c.want || !errors.Is(err, c.err) {
+			t.Errorf("NormalizeScanned(%q) = %q, %v; want %q, %v", c.raw, got, err, c.want, c.err)
+		}
+	}
+}
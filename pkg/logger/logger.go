@@ -0,0 +1,46 @@
+// Package logger настраивает zerolog по конфигурации и передаёт логгер запроса через context.Context
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"gin_main/config"
+
+	"github.com/rs/zerolog"
+)
+
+// NewLogger создаёт корневой логгер приложения. Он же становится логгером по умолчанию
+// для FromContext, чтобы код, вызванный вне HTTP-запроса, не терял записи
+func NewLogger(config *config.Config) *zerolog.Logger {
+	level, err := zerolog.ParseLevel(config.Logger.Level)
+	if err != nil {
+		level = zerolog.InfoLevel
+	}
+	var out io.Writer = os.Stdout
+	if config.Logger.Format == "console" {
+		out = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.TimeOnly}
+	} else {
+		zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	}
+	log := zerolog.New(out).Level(level).With().
+		Timestamp().
+		Str("app", config.App).
+		Str("stack", config.Stack).
+		Logger()
+	zerolog.DefaultContextLogger = &log
+	return &log
+}
+
+// WithLogger возвращает контекст, из которого FromContext достанет log
+func WithLogger(ctx context.Context, log *zerolog.Logger) context.Context {
+	return log.WithContext(ctx)
+}
+
+// FromContext возвращает логгер, привязанный к контексту (для запроса - с его request_id),
+// а если его нет - корневой логгер из NewLogger
+func FromContext(ctx context.Context) *zerolog.Logger {
+	return zerolog.Ctx(ctx)
+}
@@ -0,0 +1,103 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// cursor хранит сортировку, для которой выдан, чтобы курсор нельзя было применить к другому порядку строк
+type cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+func sortSignature(keys []SortKey) string {
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc {
+			names = append(names, "-"+key.Name)
+			continue
+		}
+		names = append(names, key.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func encodeCursor(keys []SortKey, values []any) (string, error) {
+	encoded := make([]any, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			encoded[i] = v.UTC().Format(time.RFC3339Nano)
+		case uuid.UUID:
+			encoded[i] = v.String()
+		default:
+			encoded[i] = v
+		}
+	}
+	data, err := json.Marshal(cursor{Sort: sortSignature(keys), Values: encoded})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(raw string, keys []SortKey) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sortSignature(keys) {
+		return nil, fmt.Errorf("%w: it was issued for sort %q", ErrInvalidCursor, c.Sort)
+	}
+	if len(c.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		if values[i], err = decodeValue(c.Values[i], key.Kind); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return values, nil
+}
+
+func decodeValue(value any, kind Kind) (any, error) {
+	switch kind {
+	case KindInt:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		return number.Int64()
+	case KindTime:
+		text, ok := value.(string)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		return time.Parse(time.RFC3339Nano, text)
+	case KindUUID:
+		text, ok := value.(string)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		return uuid.Parse(text)
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		return text, nil
+	}
+}
@@ -0,0 +1,183 @@
+// Package pagination реализует общий для списочных эндпоинтов контракт: keyset-курсоры,
+// сортировку вида sort=title,-dateOfWriting и ответ {items, nextCursor}
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("invalid limit")
+)
+
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindTime
+	KindUUID
+)
+
+// Field - поле, по которому разрешено сортировать; Column - SQL-выражение, оно не должно давать NULL,
+// иначе сравнение по курсору пропустит строки
+type Field struct {
+	Column string
+	Kind   Kind
+}
+
+// Spec описывает пагинацию конкретного списка
+type Spec struct {
+	Fields       map[string]Field // имя в API -> поле
+	Tiebreaker   string           // уникальное поле, всегда добавляется в конец сортировки
+	DefaultSort  string
+	DefaultLimit int
+	MaxLimit     int
+}
+
+type SortKey struct {
+	Name string
+	Field
+	Desc bool
+}
+
+// Request - разобранные параметры страницы
+type Request struct {
+	Sort  []SortKey
+	After []any // значения ключей сортировки последней строки предыдущей страницы
+	Limit int
+}
+
+// Parse проверяет sort, курсор after и limit (0 означает значение по умолчанию)
+func (s Spec) Parse(sort, after string, limit int) (Request, error) {
+	if limit == 0 {
+		limit = s.DefaultLimit
+	}
+	if limit < 1 || limit > s.MaxLimit {
+		return Request{}, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidLimit, s.MaxLimit)
+	}
+	if sort == "" {
+		sort = s.DefaultSort
+	}
+	keys, err := s.parseSort(sort)
+	if err != nil {
+		return Request{}, err
+	}
+	request := Request{Sort: keys, Limit: limit}
+	if after != "" {
+		if request.After, err = decodeCursor(after, keys); err != nil {
+			return Request{}, err
+		}
+	}
+	return request, nil
+}
+
+func (s Spec) parseSort(sort string) ([]SortKey, error) {
+	var keys []SortKey
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		field, ok := s.Fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: field %q is repeated", ErrInvalidSort, name)
+		}
+		seen[name] = true
+		keys = append(keys, SortKey{Name: name, Field: field, Desc: desc})
+	}
+	if !seen[s.Tiebreaker] {
+		keys = append(keys, SortKey{Name: s.Tiebreaker, Field: s.Fields[s.Tiebreaker]})
+	}
+	return keys, nil
+}
+
+// OrderBy возвращает выражение для ORDER BY
+func (r Request) OrderBy() string {
+	parts := make([]string, 0, len(r.Sort))
+	for _, key := range r.Sort {
+		direction := "asc"
+		if key.Desc {
+			direction = "desc"
+		}
+		parts = append(parts, key.Column+" "+direction)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Condition возвращает условие "строка идёт после курсора" с учётом направления каждого ключа:
+// (k1 > v1) or (k1 = v1 and k2 < v2) or ...; без курсора условие пустое
+func (r Request) Condition() (string, []any) {
+	if r.After == nil {
+		return "", nil
+	}
+	var branches []string
+	var args []any
+	for i, key := range r.Sort {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, r.Sort[j].Column+" = ?")
+			args = append(args, r.After[j])
+		}
+		operator := ">"
+		if key.Desc {
+			operator = "<"
+		}
+		parts = append(parts, key.Column+" "+operator+" ?")
+		args = append(args, r.After[i])
+		branches = append(branches, "("+strings.Join(parts, " and ")+")")
+	}
+	return "(" + strings.Join(branches, " or ") + ")", args
+}
+
+// FetchLimit на единицу больше Limit: лишняя строка показывает, что есть следующая страница
+func (r Request) FetchLimit() int {
+	return r.Limit + 1
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// NewPage обрезает выборку размером FetchLimit до Limit и строит курсор по последнему элементу;
+// value возвращает значение ключа сортировки name для элемента
+func NewPage[T any](items []T, request Request, value func(item T, name string) any) (Page[T], error) {
+	if items == nil {
+		items = []T{}
+	}
+	if len(items) <= request.Limit {
+		return Page[T]{Items: items}, nil
+	}
+	items = items[:request.Limit]
+	last := items[len(items)-1]
+	values := make([]any, 0, len(request.Sort))
+	for _, key := range request.Sort {
+		values = append(values, value(last, key.Name))
+	}
+	cursor, err := encodeCursor(request.Sort, values)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return Page[T]{Items: items, NextCursor: cursor}, nil
+}
+
+// Map переводит элементы страницы в другой тип, сохраняя курсор
+func Map[T, R any](page Page[T], convert func(T) (R, error)) (Page[R], error) {
+	items := make([]R, 0, len(page.Items))
+	for _, item := range page.Items {
+		converted, err := convert(item)
+		if err != nil {
+			return Page[R]{}, err
+		}
+		items = append(items, converted)
+	}
+	return Page[R]{Items: items, NextCursor: page.NextCursor}, nil
+}
@@ -0,0 +1,114 @@
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var testSpec = Spec{
+	Fields: map[string]Field{
+		"id":    {Column: "id", Kind: KindUUID},
+		"title": {Column: "title", Kind: KindString},
+		"year":  {Column: "year", Kind: KindTime},
+		"count": {Column: "count", Kind: KindInt},
+	},
+	Tiebreaker:   "id",
+	DefaultSort:  "title",
+	DefaultLimit: 2,
+	MaxLimit:     10,
+}
+
+type row struct {
+	ID    uuid.UUID
+	Title string
+	Year  time.Time
+	Count int
+}
+
+func rowValue(r row, name string) any {
+	switch name {
+	case "title":
+		return r.Title
+	case "year":
+		return r.Year
+	case "count":
+		return r.Count
+	default:
+		return r.ID
+	}
+}
+
+func TestParseSortAddsTiebreaker(t *testing.T) {
+	request, err := testSpec.Parse("title,-year", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := request.OrderBy(); got != "title asc, year desc, id asc" {
+		t.Errorf("OrderBy() = %q", got)
+	}
+	if request.Limit != 2 {
+		t.Errorf("Limit = %d, want default 2", request.Limit)
+	}
+	for _, sort := range []string{"unknown", "title,title", ""} {
+		if _, err := (Spec{Fields: testSpec.Fields, Tiebreaker: "id", MaxLimit: 1}).Parse(sort, "", 1); !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidSort", sort, err)
+		}
+	}
+	if _, err := testSpec.Parse("", "", 11); !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("limit above max: error = %v", err)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	request, err := testSpec.Parse("-count,year", "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := []row{
+		{ID: uuid.New(), Count: 5, Year: time.Date(1869, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Count: 4, Year: time.Date(1877, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Count: 3},
+	}
+	page, err := NewPage(rows, request, rowValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 || page.NextCursor == "" {
+		t.Fatalf("page = %+v, want 2 items and a cursor", page)
+	}
+
+	next, err := testSpec.Parse("-count,year", page.NextCursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []any{int64(4), rows[1].Year, rows[1].ID}
+	if !reflect.DeepEqual(next.After, want) {
+		t.Errorf("After = %#v, want %#v", next.After, want)
+	}
+	condition, args := next.Condition()
+	if condition != "((count < ?) or (count = ? and year > ?) or (count = ? and year = ? and id > ?))" || len(args) != 6 {
+		t.Errorf("Condition() = %q, %v", condition, args)
+	}
+
+	if _, err := testSpec.Parse("title", page.NextCursor, 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor reused with other sort: error = %v", err)
+	}
+	if _, err := testSpec.Parse("title", "not a cursor", 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("garbage cursor: error = %v", err)
+	}
+}
+
+func TestLastPageHasNoCursor(t *testing.T) {
+	request, _ := testSpec.Parse("", "", 2)
+	page, err := NewPage([]row{{ID: uuid.New()}}, request, rowValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.NextCursor != "" || len(page.Items) != 1 {
+		t.Errorf("page = %+v", page)
+	}
+}
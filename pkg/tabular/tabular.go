@@ -0,0 +1,132 @@
+// Package tabular читает построчно табличные файлы (CSV и XLSX) с единым интерфейсом
+package tabular
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+// Reader отдаёт строки по одной; после последней строки Next возвращает io.EOF.
+// Line - номер в файле строки, которую последней вернул Next, начиная с 1: пустые строки CSV пропускаются, но учитываются
+type Reader interface {
+	Next() ([]string, error)
+	Line() int
+	Close() error
+}
+
+// FormatFromName определяет формат по расширению файла
+func FormatFromName(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(name, ".xlsx"):
+		return FormatXLSX
+	default:
+		return ""
+	}
+}
+
+// NewReader открывает r в указанном формате; для XLSX читается первый лист книги
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return &csvReader{reader: reader}, nil
+	case FormatXLSX:
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open xlsx: %w", err)
+		}
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			_ = file.Close()
+			return nil, errors.New("xlsx has no sheets")
+		}
+		rows, err := file.Rows(sheets[0])
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		return &xlsxReader{file: file, rows: rows}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	read   bool
+	line   int
+}
+
+func (c *csvReader) Next() ([]string, error) {
+	record, err := c.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		c.line = parseErr.StartLine
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.line, _ = c.reader.FieldPos(0)
+	if !c.read {
+		// Excel сохраняет CSV в UTF-8 с BOM, он попадает в имя первой колонки
+		c.read = true
+		if len(record) > 0 {
+			record[0] = strings.TrimPrefix(record[0], "\uFEFF")
+		}
+	}
+	return record, nil
+}
+
+func (c *csvReader) Line() int {
+	return c.line
+}
+
+func (c *csvReader) Close() error {
+	return nil
+}
+
+type xlsxReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+	line int
+}
+
+func (x *xlsxReader) Next() ([]string, error) {
+	if !x.rows.Next() {
+		if err := x.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	x.line++
+	return x.rows.Columns()
+}
+
+func (x *xlsxReader) Line() int {
+	return x.line
+}
+
+func (x *xlsxReader) Close() error {
+	if err := x.rows.Close(); err != nil {
+		_ = x.file.Close()
+		return err
+	}
+	return x.file.Close()
+}
@@ -0,0 +1,73 @@
+package tabular
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func readAll(t *testing.T, reader Reader) ([][]string, []int) {
+	t.Helper()
+	defer reader.Close()
+	var rows [][]string
+	var lines []int
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return rows, lines
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+		lines = append(lines, reader.Line())
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("\uFEFFtitle,year\n\"War, and Peace\",1869\n\nshort\n"), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"title", "year"}, {"War, and Peace", "1869"}, {"short"}}
+	got, lines := readAll(t, reader)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 2, 4}) {
+		t.Errorf("lines = %v, want [1 2 4]", lines)
+	}
+}
+
+func TestXLSXReader(t *testing.T) {
+	file := excelize.NewFile()
+	_ = file.SetSheetRow("Sheet1", "A1", &[]any{"title", "year"})
+	_ = file.SetSheetRow("Sheet1", "A2", &[]any{"War and Peace", "1869"})
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(&buf, FormatXLSX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"title", "year"}, {"War and Peace", "1869"}}
+	if got, _ := readAll(t, reader); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := NewReader(strings.NewReader(""), "ods"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("error = %v, want ErrUnsupportedFormat", err)
+	}
+	if FormatFromName("Catalogue.XLSX") != FormatXLSX || FormatFromName("books.txt") != "" {
+		t.Error("FormatFromName mismatch")
+	}
+}
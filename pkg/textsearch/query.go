@@ -0,0 +1,86 @@
+package textsearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Query - разобранная поисковая строка: полные слова ищутся по тексту, инициалы относятся к имени и отчеству автора
+type Query struct {
+	Words    []string
+	Initials []string
+}
+
+// Parse разбирает строку вроде "Толстой Л.Н.", "Лев Николаевич Толстой" или "war and peace";
+// одиночная буква считается инициалом, если она заглавная или за ней стоит точка, иначе отбрасывается как союз
+func Parse(s string) Query {
+	var query Query
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+			i++
+		}
+		word := strings.ToLower(string(runes[start:i]))
+		if i-start > 1 {
+			query.Words = append(query.Words, word)
+			continue
+		}
+		dotted := i < len(runes) && runes[i] == '.'
+		if unicode.IsLetter(runes[start]) && (dotted || unicode.IsUpper(runes[start])) {
+			query.Initials = append(query.Initials, word)
+		}
+	}
+	return query
+}
+
+func (q Query) Empty() bool {
+	return len(q.Words) == 0 && len(q.Initials) == 0
+}
+
+// Text возвращает полные слова через пробел, например для сравнения по триграммам
+func (q Query) Text() string {
+	return strings.Join(q.Words, " ")
+}
+
+// TSQuery собирает текст для to_tsquery: все слова с префиксным совпадением через &,
+// варианты транслитерации объединены через |. Слова содержат только буквы и цифры, поэтому экранирование не нужно
+func (q Query) TSQuery() string {
+	if len(q.Words) == 0 {
+		return ""
+	}
+	groups := make([]string, 0, 3)
+	for _, variant := range Variants(q.Text()) {
+		terms := strings.Fields(variant)
+		for i, term := range terms {
+			terms[i] = term + ":*"
+		}
+		groups = append(groups, "("+strings.Join(terms, " & ")+")")
+	}
+	return strings.Join(groups, " | ")
+}
+
+// AnyTSQuery объединяет все слова и их транслитерации через |, годится для подсветки найденного
+func (q Query) AnyTSQuery() string {
+	var terms []string
+	for _, variant := range Variants(q.Text()) {
+		for _, term := range strings.Fields(variant) {
+			terms = append(terms, term+":*")
+		}
+	}
+	return strings.Join(terms, " | ")
+}
+
+// InitialVariants возвращает инициал в обеих раскладках, например "л" и "l"
+func InitialVariants(initial string) []string {
+	variants := Variants(initial)
+	for i, v := range variants {
+		// у транслитерации может быть несколько букв (ж -> zh), для инициала достаточно первой
+		variants[i] = string([]rune(v)[:1])
+	}
+	return variants
+}
@@ -0,0 +1,55 @@
+package textsearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransliteration(t *testing.T) {
+	cases := []struct {
+		in, latin, cyrillic string
+	}{
+		{"Толстой", "tolstoy", "толстой"},
+		{"Щедрин", "shchedrin", "щедрин"},
+		{"tolstoy", "tolstoy", "толстой"},
+		{"bykov", "bykov", "быков"},
+		{"chekhov", "chekhov", "чехов"},
+	}
+	for _, c := range cases {
+		if got := ToLatin(c.in); got != c.latin {
+			t.Errorf("ToLatin(%q) = %q, want %q", c.in, got, c.latin)
+		}
+		if got := ToCyrillic(c.latin); got != c.cyrillic {
+			t.Errorf("ToCyrillic(%q) = %q, want %q", c.latin, got, c.cyrillic)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Query
+	}{
+		{"Толстой Л.Н.", Query{Words: []string{"толстой"}, Initials: []string{"л", "н"}}},
+		{"Л. Н. Толстой", Query{Words: []string{"толстой"}, Initials: []string{"л", "н"}}},
+		{"Лев Николаевич Толстой", Query{Words: []string{"лев", "николаевич", "толстой"}}},
+		{"Толстой Л Н", Query{Words: []string{"толстой"}, Initials: []string{"л", "н"}}},
+		{"  война и мир ", Query{Words: []string{"война", "мир"}}},
+	}
+	for _, c := range cases {
+		if got := Parse(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestTSQuery(t *testing.T) {
+	got := Parse("Толстой война").TSQuery()
+	want := "(толстой:* & война:*) | (tolstoy:* & voyna:*)"
+	if got != want {
+		t.Errorf("TSQuery() = %q, want %q", got, want)
+	}
+	if Parse("Л.Н.").TSQuery() != "" {
+		t.Error("TSQuery() of initials only must be empty")
+	}
+}
@@ -0,0 +1,101 @@
+package textsearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// сочетания проверяются раньше одиночных букв, поэтому порядок важен
+var latinToCyrillic = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"}, {"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ph", "ф"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"}, {"g", "г"},
+	{"h", "х"}, {"i", "и"}, {"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"},
+	{"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"},
+	{"v", "в"}, {"w", "в"}, {"x", "кс"}, {"z", "з"},
+}
+
+// ToLatin транслитерирует кириллицу в латиницу, прочие символы оставляет как есть
+func ToLatin(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ToCyrillic транслитерирует латиницу в кириллицу; y читается как й после гласной и в конце слова, иначе как ы
+func ToCyrillic(s string) string {
+	s = strings.ToLower(s)
+	var b strings.Builder
+	prevVowel := false
+	for i := 0; i < len(s); {
+		if s[i] == 'y' && !strings.HasPrefix(s[i:], "yu") && !strings.HasPrefix(s[i:], "ya") && !strings.HasPrefix(s[i:], "yo") {
+			atWordEnd := i+1 == len(s) || !isLatinLetter(s[i+1])
+			if prevVowel || atWordEnd {
+				b.WriteString("й")
+			} else {
+				b.WriteString("ы")
+			}
+			prevVowel = false
+			i++
+			continue
+		}
+		matched := false
+		for _, pair := range latinToCyrillic {
+			if strings.HasPrefix(s[i:], pair.latin) {
+				b.WriteString(pair.cyrillic)
+				prevVowel = strings.ContainsAny(pair.latin[len(pair.latin)-1:], "aeiou")
+				i += len(pair.latin)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(s[i])
+			prevVowel = false
+			i++
+		}
+	}
+	return b.String()
+}
+
+// Variants возвращает строку и её транслитерации в обе стороны без повторов
+func Variants(s string) []string {
+	s = strings.ToLower(s)
+	variants := []string{s}
+	for _, v := range []string{ToLatin(s), ToCyrillic(s)} {
+		if !contains(variants, v) {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+func isLatinLetter(c byte) bool {
+	return c < unicode.MaxASCII && unicode.IsLetter(rune(c))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+// Package tracing настраивает OpenTelemetry: провайдер трасс с экспортёром из конфигурации
+// и распространение контекста по заголовкам W3C traceparent/tracestate
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"gin_main/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Shutdown отправляет накопленные спаны и останавливает экспортёр
+type Shutdown func(ctx context.Context) error
+
+// Setup делает провайдер трасс глобальным, поэтому otel.Tracer в любом пакете пишет в него.
+// Распространение контекста включается и при выключенном экспорте, чтобы traceparent передавался дальше
+func Setup(ctx context.Context, config *config.Config) (Shutdown, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if config.Tracing.Exporter == "none" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", config.Tracing.Exporter, err)
+	}
+	serviceResource, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(config.App),
+		semconv.DeploymentEnvironmentName(config.Stack),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, config *config.Config) (sdktrace.SpanExporter, error) {
+	if config.Tracing.Exporter == "stdout" {
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	}
+	var options []otlptracehttp.Option
+	if config.Tracing.Endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpoint(config.Tracing.Endpoint))
+	}
+	if config.Tracing.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(ctx, options...)
+}